@@ -18,13 +18,25 @@ fi
 # 2. Run Migration
 tfrefactor resource aws_s3_bucket ./terraform -provider-version "~> 4.0"
 
-#. 3. For each new resource, find the corresponding bucket ID in tfstate to generate import statements
-while IFS=, read -r field1 field2
+#. 3. For each new resource, find the corresponding parent ID in tfstate to generate import statements.
+#     An optional third field is an import ID template in which {id} is replaced by the parent ID
+#     e.g. {id}_10.0.0.0/16 for an aws_route. A third field starting with "lookup:" describes a resource whose
+#     import ID is not in tfstate e.g. a security group rule, which must be looked up and imported manually.
+while IFS=, read -r field1 field2 field3
 do
+    if [[ "$field3" == lookup:* ]]; then
+      echo "[ERROR] lookup required for $field1 of $field2: ${field3#lookup:}" >> $ERRORFILE
+      echo "# terraform import $field1 <ID of ${field3#lookup:}>" >> $OUTFILE
+      continue
+    fi
     ID=$(jq -r '.values[].resources[] | select(.address == "'"$field2"'") | .values.id' $TFSTATE_JSON)
     if [ -z "$ID" ]; then
       echo "[ERROR] unable to determine import ID for $field1" >> $ERRORFILE
     fi
-    echo "terraform import $field1 $ID" >> $OUTFILE
+    IMPORT_ID=$ID
+    if [ -n "$field3" ]; then
+      IMPORT_ID=${field3//\{id\}/$ID}
+    fi
+    echo "terraform import $field1 $IMPORT_ID" >> $OUTFILE
 done < ./output/resources.csv
 
@@ -0,0 +1,122 @@
+package command
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/anGie44/ohmyhcl/tfrefactor/tfrefactor"
+	flag "github.com/spf13/pflag"
+)
+
+type DataCommand struct {
+	Meta
+	typ                   string
+	providerVersion       string
+	path                  string
+	recursive             bool
+	hclFiles              bool
+	ignoreArguments       []string
+	onlyArguments         []string
+	ignoreDataSourceNames []string
+	onlyDataSourceNames   []string
+	ignorePaths           []string
+	pluginDirs            []string
+	pluginsPath           bool
+	allowConfigPlugins    bool
+}
+
+func (d *DataCommand) Run(args []string) int {
+	cmdFlags := flag.NewFlagSet("data", flag.ContinueOnError)
+	cmdFlags.StringVarP(&d.providerVersion, "provider-version", "p", "latest", "A new provider version constraint")
+	cmdFlags.BoolVarP(&d.recursive, "recursive", "r", false, "Check a directory recursively")
+	cmdFlags.BoolVarP(&d.hclFiles, "hcl-files", "", false, "Migrate generate blocks of .hcl files e.g. terragrunt.hcl")
+	cmdFlags.StringSliceVarP(&d.ignoreArguments, "ignore-arguments", "", []string{}, "Arguments to ignore")
+	cmdFlags.StringSliceVarP(&d.onlyArguments, "only-arguments", "", []string{}, "Arguments to migrate")
+	cmdFlags.StringSliceVarP(&d.ignoreDataSourceNames, "ignore-names", "", []string{}, "Specific data source names to ignore")
+	cmdFlags.StringSliceVarP(&d.onlyDataSourceNames, "only-names", "", []string{}, "Specific data source names to migrate")
+	cmdFlags.StringSliceVarP(&d.ignorePaths, "ignore-paths", "i", []string{}, "A regular expression for paths to ignore")
+	cmdFlags.StringSliceVarP(&d.pluginDirs, "plugins-dir", "", []string{}, "Directories of migrator plugins")
+	cmdFlags.BoolVarP(&d.pluginsPath, "plugins-path", "", false, "Also search PATH for migrator plugins")
+	cmdFlags.BoolVarP(&d.allowConfigPlugins, "allow-config-plugins", "", false, "Apply the plugin settings of the configuration file")
+
+	if err := cmdFlags.Parse(args); err != nil {
+		d.UI.Error(fmt.Sprintf("failed to parse CLI arguments: %s", err))
+		return 1
+	}
+
+	if len(cmdFlags.Args()) != 2 { //nolint:gomnd
+		d.UI.Error(fmt.Sprintf("The command expects 2 arguments, but got %d", len(cmdFlags.Args())))
+		d.UI.Error(d.Help())
+		return 1
+	}
+
+	d.typ = cmdFlags.Arg(0)
+	d.path = cmdFlags.Arg(1)
+
+	config, err := tfrefactor.LoadConfig(d.Fs, d.path)
+	if err != nil {
+		d.UI.Error(err.Error())
+		return 1
+	}
+
+	option, err := tfrefactor.NewOption("data", d.typ, changedString(cmdFlags, "provider-version", d.providerVersion), nil, changedBool(cmdFlags, "recursive", d.recursive), nil, changedBool(cmdFlags, "hcl-files", d.hclFiles), d.ignoreArguments, d.onlyArguments, d.ignoreDataSourceNames, d.onlyDataSourceNames, d.ignorePaths, d.allowConfigPlugins, config)
+	if err != nil {
+		d.UI.Error(err.Error())
+		return 1
+	}
+
+	log.Printf("[INFO] Migrate data sources of type %s to provider version %s", d.typ, option.ProviderVersion)
+
+	option.PluginDirs = append(d.pluginDirs, option.PluginDirs...)
+	if cmdFlags.Changed("plugins-path") {
+		option.PluginsPath = d.pluginsPath
+	}
+
+	log.Printf("[INFO] Migrating file or dir at path: %s", d.path)
+
+	err = tfrefactor.MigrateFileOrDir(d.Fs, d.path, option)
+	if err != nil {
+		d.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+// Help returns long-form help text.
+func (d *DataCommand) Help() string {
+	helpText := `
+Usage: tfrefactor data <DATA_SOURCE_TYPE> <PATH> [options]
+Arguments
+  DATA_SOURCE_TYPE   The provider data source type (e.g. aws_iam_policy_document)
+  PATH               A path of file or directory to update
+Options:
+  --hcl-files              Also migrate the contents of generate "provider" and "versions" blocks of .hcl files (e.g. terragrunt.hcl)
+                           in directories, writing the result to <name>_migrated.hcl (default: false)
+  --ignore-arguments       The arguments in the <DATA_SOURCE_TYPE> to ignore
+                           Set the flag with values separated by commas (e.g. --ignore-arguments="source_json") or set the flag multiple times.
+  --only-arguments         The arguments in the <DATA_SOURCE_TYPE> to migrate, leaving all others unchanged
+                           Set the flag with values separated by commas (e.g. --only-arguments="source_json") or set the flag multiple times.
+  --ignore-names           The data source names of <DATA_SOURCE_TYPE> to ignore, as globs (e.g. log_*) or regular expressions between slashes (e.g. /^log_/).
+                           Globs containing a "." match the full address including the module path (e.g. module.logs.data.aws_iam_policy_document.*).
+                           Set the flag with values separated by commas (e.g. --ignore-names="example,assume_*") or set the flag multiple times.
+  --only-names             The data source names of <DATA_SOURCE_TYPE> to migrate, ignoring all others. Patterns are as for --ignore-names.
+                           Set the flag with values separated by commas (e.g. --only-names="assume_role") or set the flag multiple times.
+  -i  --ignore-paths       Regular expressions for path to ignore
+                           Set the flag with values separated by commas or set the flag multiple times.
+  --plugins-dir            Directories searched for a migrator plugin named tfrefactor-migrator-<DATA_SOURCE_TYPE>
+                           Set the flag with values separated by commas or set the flag multiple times.
+  --plugins-path           Also search PATH for a migrator plugin after the plugin directories (default: false)
+  --allow-config-plugins   Apply plugin_dirs and plugins_path of the configuration file, which are ignored otherwise as they run
+                           executables of the configuration being migrated (default: false)
+  -p  --provider-version   The provider version constraint (default: v4.0.0)
+  -r  --recursive          Check a directory recursively (default: false)
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis returns one-line help text.
+func (d *DataCommand) Synopsis() string {
+	return "Refactor deprecated data source arguments"
+}
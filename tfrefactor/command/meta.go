@@ -3,6 +3,7 @@ package command
 import (
 	"github.com/mitchellh/cli"
 	"github.com/spf13/afero"
+	flag "github.com/spf13/pflag"
 )
 
 type Meta struct {
@@ -12,3 +13,21 @@ type Meta struct {
 	// Fs is an afero filesystem.
 	Fs afero.Fs
 }
+
+// changedString returns the value of a flag if it was given on the CLI, or nil so that
+// the value of the project configuration, if any, applies.
+func changedString(flags *flag.FlagSet, name, value string) *string {
+	if !flags.Changed(name) {
+		return nil
+	}
+	return &value
+}
+
+// changedBool returns the value of a flag if it was given on the CLI, or nil so that
+// the value of the project configuration, if any, applies.
+func changedBool(flags *flag.FlagSet, name string, value bool) *bool {
+	if !flags.Changed(name) {
+		return nil
+	}
+	return &value
+}
@@ -16,9 +16,17 @@ type ResourceCommand struct {
 	path                string
 	csv                 bool
 	recursive           bool
+	ownershipControls   bool
+	hclFiles            bool
+	rulesFile           string
 	ignoreArguments     []string
+	onlyArguments       []string
 	ignoreResourceNames []string
+	onlyResourceNames   []string
 	ignorePaths         []string
+	pluginDirs          []string
+	pluginsPath         bool
+	allowConfigPlugins  bool
 }
 
 func (r *ResourceCommand) Run(args []string) int {
@@ -26,9 +34,17 @@ func (r *ResourceCommand) Run(args []string) int {
 	cmdFlags.StringVarP(&r.providerVersion, "provider-version", "p", "latest", "A new provider version constraint")
 	cmdFlags.BoolVarP(&r.csv, "csv", "c", false, "Generate .csv file with list of new resources and their parent resource")
 	cmdFlags.BoolVarP(&r.recursive, "recursive", "r", false, "Check a directory recursively")
+	cmdFlags.BoolVarP(&r.hclFiles, "hcl-files", "", false, "Migrate generate blocks of .hcl files e.g. terragrunt.hcl")
+	cmdFlags.BoolVarP(&r.ownershipControls, "ownership-controls", "", false, "Generate ownership controls for buckets with non-private ACLs and a public access block for public ACLs")
+	cmdFlags.StringVarP(&r.rulesFile, "rules-file", "", "", "A file of declarative migration rules")
 	cmdFlags.StringSliceVarP(&r.ignoreArguments, "ignore-arguments", "", []string{}, "Arguments to ignore")
+	cmdFlags.StringSliceVarP(&r.onlyArguments, "only-arguments", "", []string{}, "Arguments to migrate")
 	cmdFlags.StringSliceVarP(&r.ignoreResourceNames, "ignore-names", "", []string{}, "Specific resource names to ignore")
+	cmdFlags.StringSliceVarP(&r.onlyResourceNames, "only-names", "", []string{}, "Specific resource names to migrate")
 	cmdFlags.StringSliceVarP(&r.ignorePaths, "ignore-paths", "i", []string{}, "A regular expression for paths to ignore")
+	cmdFlags.StringSliceVarP(&r.pluginDirs, "plugins-dir", "", []string{}, "Directories of migrator plugins")
+	cmdFlags.BoolVarP(&r.pluginsPath, "plugins-path", "", false, "Also search PATH for migrator plugins")
+	cmdFlags.BoolVarP(&r.allowConfigPlugins, "allow-config-plugins", "", false, "Apply the plugin settings of the configuration file")
 
 	if err := cmdFlags.Parse(args); err != nil {
 		r.UI.Error(fmt.Sprintf("failed to parse CLI arguments: %s", err))
@@ -44,13 +60,38 @@ func (r *ResourceCommand) Run(args []string) int {
 	r.typ = cmdFlags.Arg(0)
 	r.path = cmdFlags.Arg(1)
 
-	log.Printf("[INFO] Migrate resources of type %s to provider version %s", r.typ, r.providerVersion)
-	option, err := tfrefactor.NewOption("resource", r.typ, r.providerVersion, r.csv, r.recursive, r.ignoreArguments, r.ignoreResourceNames, r.ignorePaths)
+	config, err := tfrefactor.LoadConfig(r.Fs, r.path)
 	if err != nil {
 		r.UI.Error(err.Error())
 		return 1
 	}
 
+	option, err := tfrefactor.NewOption("resource", r.typ, changedString(cmdFlags, "provider-version", r.providerVersion), changedBool(cmdFlags, "csv", r.csv), changedBool(cmdFlags, "recursive", r.recursive), changedBool(cmdFlags, "ownership-controls", r.ownershipControls), changedBool(cmdFlags, "hcl-files", r.hclFiles), r.ignoreArguments, r.onlyArguments, r.ignoreResourceNames, r.onlyResourceNames, r.ignorePaths, r.allowConfigPlugins, config)
+	if err != nil {
+		r.UI.Error(err.Error())
+		return 1
+	}
+
+	log.Printf("[INFO] Migrate resources of type %s to provider version %s", r.typ, option.ProviderVersion)
+
+	if r.rulesFile == "" && config != nil {
+		r.rulesFile = config.Path(config.RulesFile)
+	}
+
+	if r.rulesFile != "" {
+		rules, err := tfrefactor.LoadRules(r.Fs, r.rulesFile)
+		if err != nil {
+			r.UI.Error(err.Error())
+			return 1
+		}
+		option.Rules = rules
+	}
+
+	option.PluginDirs = append(r.pluginDirs, option.PluginDirs...)
+	if cmdFlags.Changed("plugins-path") {
+		option.PluginsPath = r.pluginsPath
+	}
+
 	log.Printf("[INFO] Migrating file or dir at path: %s", r.path)
 
 	err = tfrefactor.MigrateFileOrDir(r.Fs, r.path, option)
@@ -67,15 +108,32 @@ func (r *ResourceCommand) Help() string {
 	helpText := `
 Usage: tfrefactor resource <RESOURCE_TYPE> <PATH> [options]
 Arguments
-  RESOURCE_TYPE      The provider resource type (e.g. aws_s3_bucket)
+  RESOURCE_TYPE      The provider resource type (e.g. aws_s3_bucket, aws_s3_bucket_object, aws_iam_role, aws_network_acl, aws_route_table, aws_secretsmanager_secret, aws_security_group)
   PATH               A path of file or directory to update
 Options:
   --ignore-arguments       The arguments in the <RESOURCE_TYPE> to ignore
                            Set the flag with values separated by commas (e.g. --ignore-arguments="acl,grant") or set the flag multiple times.
-  --ignore-names           The resource names of <RESOURCE_TYPE> to ignore
-                           Set the flag with values separated by commas (e.g. --ignore-names="example,log_bucket") or set the flag multiple times.
+  --only-arguments         The arguments in the <RESOURCE_TYPE> to migrate, leaving all others unchanged
+                           Set the flag with values separated by commas (e.g. --only-arguments="versioning,server_side_encryption_configuration") or set the flag multiple times.
+  --ignore-names           The resource names of <RESOURCE_TYPE> to ignore, as globs (e.g. log_*) or regular expressions between slashes (e.g. /^log_/).
+                           Globs containing a "." match the full address including the module path (e.g. module.logs.aws_s3_bucket.*).
+                           Set the flag with values separated by commas (e.g. --ignore-names="example,log_*") or set the flag multiple times.
+  --only-names             The resource names of <RESOURCE_TYPE> to migrate, ignoring all others. Patterns are as for --ignore-names.
+                           Set the flag with values separated by commas (e.g. --only-names="module.logs.*") or set the flag multiple times.
   -i  --ignore-paths       Regular expressions for path to ignore
                            Set the flag with values separated by commas or set the flag multiple times.
+  --plugins-dir            Directories searched for a migrator plugin named tfrefactor-migrator-<RESOURCE_TYPE>
+                           Set the flag with values separated by commas or set the flag multiple times.
+  --plugins-path           Also search PATH for a migrator plugin after the plugin directories (default: false)
+  --allow-config-plugins   Apply plugin_dirs and plugins_path of the configuration file, which are ignored otherwise as they run
+                           executables of the configuration being migrated (default: false)
+  --hcl-files              Also migrate the contents of generate "provider" and "versions" blocks of .hcl files (e.g. terragrunt.hcl)
+                           in directories, writing the result to <name>_migrated.hcl (default: false)
+  --ownership-controls     Generate aws_s3_bucket_ownership_controls resources alongside aws_s3_bucket_acl resources with a literal
+                           non-private ACL or grants, and aws_s3_bucket_public_access_block resources allowing public ACLs only
+                           for public ACLs (e.g. public-read) or grants to all users or any AWS account (default: false)
+  --rules-file             A file of declarative rules migrating arguments and blocks of <RESOURCE_TYPE> to new resources,
+                           in addition to built-in migrations, if any
   -c  --csv    			   Generate a CSV file of new resources and their parent resource (default: false)           
   -p  --provider-version   The provider version constraint (default: v4.0.0)
   -r  --recursive          Check a directory recursively (default: false)
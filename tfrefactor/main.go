@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/anGie44/ohmyhcl/tfrefactor/command"
+	"github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/logutils"
 	"github.com/mitchellh/cli"
 	"github.com/spf13/afero"
@@ -45,6 +46,9 @@ func main() {
 		UI.Error(fmt.Sprintf("Failed to execute CLI: %s", err))
 	}
 
+	// Kill migrator plugin processes which were not stopped e.g. on error
+	plugin.CleanupClients()
+
 	os.Exit(exitStatus)
 }
 
@@ -74,6 +78,11 @@ func initCommands() map[string]cli.CommandFactory {
 	}
 
 	commands := map[string]cli.CommandFactory{
+		"data": func() (cli.Command, error) {
+			return &command.DataCommand{
+				Meta: meta,
+			}, nil
+		},
 		"resource": func() (cli.Command, error) {
 			return &command.ResourceCommand{
 				Meta: meta,
@@ -0,0 +1,140 @@
+package tfrefactor
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+const (
+	// ignoreDirective in a comment preceding a block, or on the line of its opening brace,
+	// ignores the block e.g. "# tfrefactor:ignore", or only some of its arguments
+	// e.g. "# tfrefactor:ignore=acl,versioning".
+	ignoreDirective = "tfrefactor:ignore"
+
+	// ignoreFileDirective in a comment anywhere in a file ignores all of its blocks, or only
+	// some of their arguments e.g. "# tfrefactor:ignore-file=acl".
+	ignoreFileDirective = "tfrefactor:ignore-file"
+)
+
+// ignoreAnnotation is the set of ignore directives which apply to a block.
+type ignoreAnnotation struct {
+	// If all is true, the block is ignored
+	all bool
+
+	// arguments (and nested blocks) of the block to ignore
+	arguments []string
+}
+
+// skipResource returns whether a block is ignored. A nil annotation ignores nothing.
+func (a *ignoreAnnotation) skipResource() bool {
+	return a != nil && a.all
+}
+
+// skipArgument returns whether an argument or nested block of a block is ignored.
+func (a *ignoreAnnotation) skipArgument(arg string) bool {
+	if a == nil {
+		return false
+	}
+
+	if a.all {
+		return true
+	}
+
+	for _, argument := range a.arguments {
+		if argument == arg {
+			return true
+		}
+	}
+
+	return false
+}
+
+// merge returns the combination of two annotations, either of which may be nil.
+func (a *ignoreAnnotation) merge(other *ignoreAnnotation) *ignoreAnnotation {
+	if a == nil {
+		return other
+	}
+
+	if other == nil {
+		return a
+	}
+
+	return &ignoreAnnotation{
+		all:       a.all || other.all,
+		arguments: appendStrings(a.arguments, other.arguments...),
+	}
+}
+
+// fileIgnoreAnnotation returns the annotation of the file-level directives of a file, or nil if there are none.
+func fileIgnoreAnnotation(f *hclwrite.File) *ignoreAnnotation {
+	var annotation *ignoreAnnotation
+	for _, t := range f.BuildTokens(nil) {
+		if t.Type != hclsyntax.TokenComment {
+			continue
+		}
+
+		annotation = annotation.merge(parseIgnoreDirective(t.Bytes, ignoreFileDirective))
+	}
+
+	return annotation
+}
+
+// blockIgnoreAnnotation returns the annotation of a block combined with the annotation of its file, or nil
+// if neither ignores anything. Directives are read from the comments directly preceding the block and
+// the comment on the line of its opening brace.
+func blockIgnoreAnnotation(block *hclwrite.Block, fileAnnotation *ignoreAnnotation) *ignoreAnnotation {
+	annotation := fileAnnotation
+
+	tokens := block.BuildTokens(nil)
+	for i, t := range tokens {
+		if t.Type == hclsyntax.TokenComment {
+			annotation = annotation.merge(parseIgnoreDirective(t.Bytes, ignoreDirective))
+			continue
+		}
+
+		if t.Type != hclsyntax.TokenOBrace {
+			continue
+		}
+
+		if i+1 < len(tokens) && tokens[i+1].Type == hclsyntax.TokenComment {
+			annotation = annotation.merge(parseIgnoreDirective(tokens[i+1].Bytes, ignoreDirective))
+		}
+		break
+	}
+
+	return annotation
+}
+
+// parseIgnoreDirective returns the annotation of a comment if it starts with a directive, or nil.
+// Text following the directive e.g. a reason is allowed.
+func parseIgnoreDirective(comment []byte, directive string) *ignoreAnnotation {
+	text := strings.TrimSpace(string(comment))
+	for _, prefix := range []string{"#", "//", "/*"} {
+		text = strings.TrimPrefix(text, prefix)
+	}
+	text = strings.TrimSpace(strings.TrimSuffix(text, "*/"))
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if fields[0] == directive {
+		return &ignoreAnnotation{all: true}
+	}
+
+	if !strings.HasPrefix(fields[0], directive+"=") {
+		return nil
+	}
+
+	var arguments []string
+	for _, arg := range strings.Split(strings.TrimPrefix(fields[0], directive+"="), ",") {
+		if arg = strings.TrimSpace(arg); arg != "" {
+			arguments = append(arguments, arg)
+		}
+	}
+
+	return &ignoreAnnotation{arguments: arguments}
+}
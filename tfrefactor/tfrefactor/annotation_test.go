@@ -0,0 +1,187 @@
+package tfrefactor
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestParseIgnoreDirective(t *testing.T) {
+	cases := []struct {
+		comment string
+		want    *ignoreAnnotation
+	}{
+		{
+			comment: "# tfrefactor:ignore\n",
+			want:    &ignoreAnnotation{all: true},
+		},
+		{
+			comment: "// tfrefactor:ignore managed by another team\n",
+			want:    &ignoreAnnotation{all: true},
+		},
+		{
+			comment: "# tfrefactor:ignore=acl, versioning\n",
+			want:    &ignoreAnnotation{arguments: []string{"acl"}},
+		},
+		{
+			comment: "/* tfrefactor:ignore=acl,versioning */",
+			want:    &ignoreAnnotation{arguments: []string{"acl", "versioning"}},
+		},
+		{
+			comment: "# tfrefactor:ignore-file\n",
+			want:    nil,
+		},
+		{
+			comment: "# do not tfrefactor:ignore\n",
+			want:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		if got := parseIgnoreDirective([]byte(tc.comment), ignoreDirective); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseIgnoreDirective() with comment = %q returns %#v, but want = %#v", tc.comment, got, tc.want)
+		}
+	}
+}
+
+func TestMigrateHCLIgnoreAnnotations(t *testing.T) {
+	cases := []struct {
+		src  string
+		want []string
+	}{
+		{
+			src: `
+# tfrefactor:ignore
+resource "aws_s3_bucket" "ignored" {
+  acl = "private"
+}
+
+resource "aws_s3_bucket" "test" {
+  acl = "private"
+}
+`,
+			want: []string{
+				"aws_s3_bucket_acl.test_acl,aws_s3_bucket.test",
+			},
+		},
+		{
+			src: `
+# Logs of the application
+# tfrefactor:ignore=acl,versioning
+resource "aws_s3_bucket" "test" {
+  acl = "private"
+
+  versioning {
+    enabled = true
+  }
+
+  logging {
+    target_bucket = "logs"
+  }
+}
+
+resource "aws_s3_bucket" "other" { # tfrefactor:ignore=logging
+  acl = "private"
+}
+`,
+			want: []string{
+				"aws_s3_bucket_logging.test_logging,aws_s3_bucket.test",
+				"aws_s3_bucket_acl.other_acl,aws_s3_bucket.other",
+			},
+		},
+		{
+			src: `
+# tfrefactor:ignore-file=acl
+
+resource "aws_s3_bucket" "test" {
+  acl = "private"
+
+  versioning {
+    enabled = true
+  }
+}
+
+resource "aws_s3_bucket" "ignored" { # tfrefactor:ignore
+  versioning {
+    enabled = true
+  }
+}
+`,
+			want: []string{
+				"aws_s3_bucket_versioning.test_versioning,aws_s3_bucket.test",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		o := Option{
+			MigratorType: "resource",
+			ResourceType: ResourceTypeAwsS3Bucket,
+		}
+
+		got, err := MigrateHCL(strings.NewReader(tc.src), &bytes.Buffer{}, "test.tf", o)
+		if err != nil {
+			t.Fatalf("MigrateHCL() with src = %s returns unexpected err: %s", tc.src, err)
+		}
+
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("MigrateHCL() with src = %s returns %#v, but want = %#v", tc.src, got, tc.want)
+		}
+	}
+}
+
+func TestMigrateHCLModuleIgnoreAnnotations(t *testing.T) {
+	files := map[string]string{
+		"module/main.tf": `
+# tfrefactor:ignore
+resource "aws_s3_bucket_object" "ignored" {
+  key = "ignored"
+}
+
+resource "aws_s3_bucket_object" "test" {
+  key = "test"
+}
+`,
+	}
+
+	fs := afero.NewMemMapFs()
+	for name, src := range files {
+		if err := afero.WriteFile(fs, name, []byte(src), 0644); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+	}
+
+	module, err := LoadModule(fs, "module")
+	if err != nil {
+		t.Fatalf("LoadModule() returns unexpected err: %s", err)
+	}
+
+	src := `
+output "keys" {
+  value = [aws_s3_bucket_object.ignored.key, aws_s3_bucket_object.test.key]
+}
+`
+	want := `
+output "keys" {
+  value = [aws_s3_bucket_object.ignored.key, aws_s3_object.test.key]
+}
+`
+
+	o := Option{
+		MigratorType: "resource",
+		ResourceType: ResourceTypeAwsS3BucketObject,
+		Module:       module,
+	}
+
+	w := &bytes.Buffer{}
+	if _, err := MigrateHCL(strings.NewReader(src), w, "module/outputs.tf", o); err != nil {
+		t.Fatalf("MigrateHCL() with src = %s returns unexpected err: %s", src, err)
+	}
+
+	if got := w.String(); got != want {
+		t.Errorf("MigrateHCL() with src = %s returns %s, but want = %s", src, got, want)
+	}
+}
@@ -0,0 +1,41 @@
+package tfrefactor
+
+// ArgumentFilter decides which arguments and blocks of a resource or data source to migrate.
+type ArgumentFilter struct {
+	ignore []string
+	only   []string
+}
+
+// NewArgumentFilter returns a filter skipping the ignored arguments or, if there are any
+// only arguments, all arguments except those.
+func NewArgumentFilter(ignoreArguments, onlyArguments []string) *ArgumentFilter {
+	return &ArgumentFilter{
+		ignore: ignoreArguments,
+		only:   onlyArguments,
+	}
+}
+
+// Skip returns whether to skip an argument or block. A nil filter skips nothing.
+func (af *ArgumentFilter) Skip(arg string) bool {
+	if af == nil {
+		return false
+	}
+
+	for _, argument := range af.ignore {
+		if argument == arg {
+			return true
+		}
+	}
+
+	if len(af.only) == 0 {
+		return false
+	}
+
+	for _, argument := range af.only {
+		if argument == arg {
+			return false
+		}
+	}
+
+	return true
+}
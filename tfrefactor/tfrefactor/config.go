@@ -0,0 +1,190 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/afero"
+)
+
+// ConfigFilename is the name of the project configuration file, discovered from
+// the path to migrate upward.
+const ConfigFilename = ".tfrefactor.hcl"
+
+// Config is a project configuration of options which are otherwise given as CLI flags e.g.
+//
+//	provider_version = "~> 4.0"
+//	recursive        = true
+//	ignore_paths     = ["examples/"]
+//
+//	migrator "aws_s3_bucket" {
+//	  ignore_arguments   = ["grant"]
+//	  ownership_controls = true
+//	}
+//
+//	directory "modules/legacy" {
+//	  provider_version = "~> 3.0"
+//
+//	  migrator "aws_s3_bucket" {
+//	    ignore_names = ["log_bucket"]
+//	  }
+//	}
+//
+// CLI flags which were given take precedence over the configuration, even if set to their
+// default value (e.g. --csv=false); lists are combined.
+type Config struct {
+	ProviderVersion string   `hcl:"provider_version,optional"`
+	Csv             bool     `hcl:"csv,optional"`
+	Recursive       bool     `hcl:"recursive,optional"`
+	HclFiles        bool     `hcl:"hcl_files,optional"`
+	IgnorePaths     []string `hcl:"ignore_paths,optional"`
+	PluginDirs      []string `hcl:"plugin_dirs,optional"`
+	PluginsPath     bool     `hcl:"plugins_path,optional"`
+	RulesFile       string   `hcl:"rules_file,optional"`
+
+	Migrators   []*MigratorConfig  `hcl:"migrator,block"`
+	Directories []*DirectoryConfig `hcl:"directory,block"`
+
+	// directory of the configuration file, which relative paths are resolved against
+	dir string
+}
+
+// MigratorConfig is the configuration of the migrator of a resource or data source type.
+type MigratorConfig struct {
+	Type              string   `hcl:"type,label"`
+	IgnoreArguments   []string `hcl:"ignore_arguments,optional"`
+	OnlyArguments     []string `hcl:"only_arguments,optional"`
+	IgnoreNames       []string `hcl:"ignore_names,optional"`
+	OnlyNames         []string `hcl:"only_names,optional"`
+	OwnershipControls bool     `hcl:"ownership_controls,optional"`
+}
+
+// DirectoryConfig overrides the configuration for files in a directory (relative to the
+// configuration file) and its subdirectories.
+type DirectoryConfig struct {
+	Path            string            `hcl:"path,label"`
+	ProviderVersion string            `hcl:"provider_version,optional"`
+	Migrators       []*MigratorConfig `hcl:"migrator,block"`
+}
+
+// FindConfig returns the path of the configuration file in the directory of a given path
+// or its nearest parent directory, or "" if there is none.
+func FindConfig(fs afero.Fs, path string) (string, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to find config file: %s", err)
+	}
+
+	if isDir, err := afero.IsDir(fs, dir); err == nil && !isDir {
+		dir = filepath.Dir(dir)
+	}
+
+	for {
+		filename := filepath.Join(dir, ConfigFilename)
+		if ok, _ := afero.Exists(fs, filename); ok {
+			return filename, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadConfig reads the configuration file discovered from a given path, or returns nil if there is none.
+func LoadConfig(fs afero.Fs, path string) (*Config, error) {
+	filename, err := FindConfig(fs, path)
+	if err != nil || filename == "" {
+		return nil, err
+	}
+
+	log.Printf("[INFO] Using config file: %s", filename)
+
+	src, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %s", err)
+	}
+
+	return ParseConfig(src, filename)
+}
+
+// ParseConfig parses a configuration from the HCL source of a configuration file.
+func ParseConfig(src []byte, filename string) (*Config, error) {
+	f, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse config file: %s", diags)
+	}
+
+	config := &Config{}
+	if diags := gohcl.DecodeBody(f.Body, nil, config); diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode config file: %s", diags)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode config file: %s", err)
+	}
+	config.dir = dir
+
+	return config, nil
+}
+
+// Path resolves a path of the configuration relative to the configuration file.
+func (c *Config) Path(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.dir, path)
+}
+
+// Migrator returns the configuration of the migrator of a resource or data source type, or nil if there is none.
+func (c *Config) Migrator(resourceType string) *MigratorConfig {
+	if c == nil {
+		return nil
+	}
+	return findMigratorConfig(c.Migrators, resourceType)
+}
+
+// directories returns the directory overrides which apply to a directory, from the outermost.
+func (c *Config) directories(dir string) []*DirectoryConfig {
+	if c == nil || len(c.Directories) == 0 {
+		return nil
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	var directories []*DirectoryConfig
+	for _, d := range c.Directories {
+		path := c.Path(d.Path)
+		if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+			directories = append(directories, d)
+		}
+	}
+
+	sort.SliceStable(directories, func(i, j int) bool {
+		return len(c.Path(directories[i].Path)) < len(c.Path(directories[j].Path))
+	})
+
+	return directories
+}
+
+func findMigratorConfig(migrators []*MigratorConfig, resourceType string) *MigratorConfig {
+	for _, m := range migrators {
+		if m.Type == resourceType {
+			return m
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package tfrefactor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+const testConfig = `
+provider_version = "~> 4.0"
+recursive        = true
+ignore_paths     = ["examples/"]
+plugin_dirs      = ["plugins"]
+
+migrator "aws_s3_bucket" {
+  ignore_arguments   = ["grant"]
+  ownership_controls = true
+}
+
+directory "modules/legacy" {
+  provider_version = "~> 3.0"
+
+  migrator "aws_s3_bucket" {
+    ignore_names = ["log_bucket"]
+  }
+}
+
+directory "modules/legacy/bucket" {
+  migrator "aws_s3_bucket" {
+    ignore_arguments = ["acl"]
+  }
+}
+`
+
+func TestLoadConfig(t *testing.T) {
+	cases := []struct {
+		path string
+		want string
+	}{
+		{
+			path: "/work/main.tf",
+			want: "/work",
+		},
+		{
+			path: "/work/modules/legacy",
+			want: "/work",
+		},
+		{
+			path: "/work/modules/override",
+			want: "/work/modules/override",
+		},
+		{
+			path: "/other",
+			want: "",
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	for filename, src := range map[string]string{
+		"/work/.tfrefactor.hcl":                  testConfig,
+		"/work/main.tf":                          "",
+		"/work/modules/legacy/main.tf":           "",
+		"/work/modules/override/.tfrefactor.hcl": `csv = true`,
+		"/other/main.tf":                         "",
+	} {
+		if err := afero.WriteFile(fs, filename, []byte(src), 0644); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+	}
+
+	for _, tc := range cases {
+		config, err := LoadConfig(fs, tc.path)
+		if err != nil {
+			t.Fatalf("LoadConfig() with path = %s returns unexpected err: %s", tc.path, err)
+		}
+
+		if tc.want == "" {
+			if config != nil {
+				t.Errorf("LoadConfig() with path = %s returns %#v, but want = nil", tc.path, config)
+			}
+			continue
+		}
+
+		if config == nil || config.dir != tc.want {
+			t.Errorf("LoadConfig() with path = %s returns %#v, but want config in %s", tc.path, config, tc.want)
+		}
+	}
+}
+
+func TestNewOptionConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(testConfig), "/work/.tfrefactor.hcl")
+	if err != nil {
+		t.Fatalf("ParseConfig() returns unexpected err: %s", err)
+	}
+
+	cases := []struct {
+		dir                     string
+		providerVersion         string
+		ignoreArguments         []string
+		wantProviderVersion     string
+		wantIgnoreArguments     []string
+		wantIgnoreResourceNames []string
+	}{
+		{
+			dir:                 "/work",
+			wantProviderVersion: "~> 4.0",
+			wantIgnoreArguments: []string{"grant"},
+		},
+		{
+			dir:                     "/work/modules/legacy",
+			wantProviderVersion:     "~> 3.0",
+			wantIgnoreArguments:     []string{"grant"},
+			wantIgnoreResourceNames: []string{"log_bucket"},
+		},
+		{
+			dir:                     "/work/modules/legacy/bucket",
+			providerVersion:         "~> 4.9",
+			ignoreArguments:         []string{"policy"},
+			wantProviderVersion:     "~> 4.9",
+			wantIgnoreArguments:     []string{"policy", "grant", "acl"},
+			wantIgnoreResourceNames: []string{"log_bucket"},
+		},
+		{
+			dir:                 "/work/modules/legacy_v2",
+			wantProviderVersion: "~> 4.0",
+			wantIgnoreArguments: []string{"grant"},
+		},
+		{
+			// latest given explicitly on the CLI
+			dir:                     "/work/modules/legacy",
+			providerVersion:         "latest",
+			wantProviderVersion:     "latest",
+			wantIgnoreArguments:     []string{"grant"},
+			wantIgnoreResourceNames: []string{"log_bucket"},
+		},
+	}
+
+	for _, tc := range cases {
+		var providerVersion *string
+		if tc.providerVersion != "" {
+			providerVersion = &tc.providerVersion
+		}
+
+		o, err := NewOption("resource", ResourceTypeAwsS3Bucket, providerVersion, nil, nil, nil, nil, tc.ignoreArguments, nil, nil, nil, nil, true, config)
+		if err != nil {
+			t.Fatalf("NewOption() returns unexpected err: %s", err)
+		}
+
+		if !o.Recursive || !o.OwnershipControls || len(o.IgnorePaths) != 1 {
+			t.Errorf("NewOption() returns %#v, but want recursive, ownership controls and ignore paths of the config", o)
+		}
+
+		if want := []string{"/work/plugins"}; !reflect.DeepEqual(o.PluginDirs, want) {
+			t.Errorf("NewOption() returns plugin dirs %#v, but want = %#v", o.PluginDirs, want)
+		}
+
+		o = o.ForDir(tc.dir)
+
+		if o.ProviderVersion != tc.wantProviderVersion {
+			t.Errorf("ForDir() with dir = %s returns provider version %s, but want = %s", tc.dir, o.ProviderVersion, tc.wantProviderVersion)
+		}
+
+		if !reflect.DeepEqual(o.IgnoreArguments, tc.wantIgnoreArguments) {
+			t.Errorf("ForDir() with dir = %s returns ignore arguments %#v, but want = %#v", tc.dir, o.IgnoreArguments, tc.wantIgnoreArguments)
+		}
+
+		if len(o.IgnoreResourceNames) != 0 || len(tc.wantIgnoreResourceNames) != 0 {
+			if !reflect.DeepEqual(o.IgnoreResourceNames, tc.wantIgnoreResourceNames) {
+				t.Errorf("ForDir() with dir = %s returns ignore names %#v, but want = %#v", tc.dir, o.IgnoreResourceNames, tc.wantIgnoreResourceNames)
+			}
+		}
+	}
+}
+
+func TestNewOptionExplicitFlags(t *testing.T) {
+	config, err := ParseConfig([]byte(testConfig+`
+csv       = true
+hcl_files = true
+`), "/work/.tfrefactor.hcl")
+	if err != nil {
+		t.Fatalf("ParseConfig() returns unexpected err: %s", err)
+	}
+
+	// Flags given on the CLI turn off the settings of the config
+	disabled := false
+	o, err := NewOption("resource", ResourceTypeAwsS3Bucket, nil, &disabled, &disabled, &disabled, &disabled, nil, nil, nil, nil, nil, false, config)
+	if err != nil {
+		t.Fatalf("NewOption() returns unexpected err: %s", err)
+	}
+
+	if o.Csv || o.Recursive || o.OwnershipControls || o.HclFiles {
+		t.Errorf("NewOption() returns %#v, but want csv, recursive, ownership controls and hcl files of the CLI flags", o)
+	}
+
+	o, err = NewOption("resource", ResourceTypeAwsS3Bucket, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, false, config)
+	if err != nil {
+		t.Fatalf("NewOption() returns unexpected err: %s", err)
+	}
+
+	if !o.Csv || !o.Recursive || !o.OwnershipControls || !o.HclFiles {
+		t.Errorf("NewOption() returns %#v, but want csv, recursive, ownership controls and hcl files of the config", o)
+	}
+}
+
+func TestNewOptionConfigPlugins(t *testing.T) {
+	config, err := ParseConfig([]byte(testConfig+`
+plugins_path = true
+`), "/work/.tfrefactor.hcl")
+	if err != nil {
+		t.Fatalf("ParseConfig() returns unexpected err: %s", err)
+	}
+
+	// Plugins of the config are only run if allowed on the CLI
+	o, err := NewOption("resource", ResourceTypeAwsS3Bucket, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, false, config)
+	if err != nil {
+		t.Fatalf("NewOption() returns unexpected err: %s", err)
+	}
+
+	if len(o.PluginDirs) != 0 || o.PluginsPath {
+		t.Errorf("NewOption() returns plugin dirs %#v and plugins path %t, but want none", o.PluginDirs, o.PluginsPath)
+	}
+
+	o, err = NewOption("resource", ResourceTypeAwsS3Bucket, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, true, config)
+	if err != nil {
+		t.Fatalf("NewOption() returns unexpected err: %s", err)
+	}
+
+	if want := []string{"/work/plugins"}; !reflect.DeepEqual(o.PluginDirs, want) || !o.PluginsPath {
+		t.Errorf("NewOption() returns plugin dirs %#v and plugins path %t, but want = %#v and true", o.PluginDirs, o.PluginsPath, want)
+	}
+}
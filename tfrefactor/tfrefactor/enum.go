@@ -4,28 +4,69 @@ import "fmt"
 
 type Resource int64
 
-var ResourceMap map[string]string
-
 const (
 	AccelerationStatus                = "acceleration_status"
 	Acl                               = "acl"
 	AccelerateConfiguration           = "accelerate_configuration"
 	CorsConfiguration                 = "cors_configuration"
 	CorsRule                          = "cors_rule"
+	Egress                            = "egress"
 	Grant                             = "grant"
+	Ingress                           = "ingress"
+	InlinePolicy                      = "inline_policy"
 	LifecycleConfiguration            = "lifecycle_configuration"
 	LifecycleRule                     = "lifecycle_rule"
 	Logging                           = "logging"
+	ManagedPolicyArns                 = "managed_policy_arns"
 	ObjectLockConfiguration           = "object_lock_configuration"
+	OverrideJson                      = "override_json"
+	OverridePolicyDocuments           = "override_policy_documents"
+	OwnershipControls                 = "ownership_controls"
 	Policy                            = "policy"
+	PolicyAttachment                  = "policy_attachment"
+	PublicAccessBlock                 = "public_access_block"
 	ReplicationConfiguration          = "replication_configuration"
 	RequestPayer                      = "request_payer"
 	RequestPaymentConfiguration       = "request_payment_configuration"
+	Rotation                          = "rotation"
+	Route                             = "route"
+	RotationEnabled                   = "rotation_enabled"
+	RotationLambdaArn                 = "rotation_lambda_arn"
+	RotationRules                     = "rotation_rules"
 	ServerSideEncryptionConfiguration = "server_side_encryption_configuration"
+	SourceJson                        = "source_json"
+	SourcePolicyDocuments             = "source_policy_documents"
 	Versioning                        = "versioning"
 	Website                           = "website"
 	WebsiteConfiguration              = "website_configuration"
 
+	ResourceTypeAwsS3BucketObject  = "aws_s3_bucket_object"
+	ResourceTypeAwsS3BucketObjects = "aws_s3_bucket_objects"
+	ResourceTypeAwsS3Object        = "aws_s3_object"
+	ResourceTypeAwsS3Objects       = "aws_s3_objects"
+
+	ResourceTypeAwsAutoscalingAttachment       = "aws_autoscaling_attachment"
+	ResourceTypeAwsAutoscalingGroup            = "aws_autoscaling_group"
+	ResourceTypeAwsDbInstance                  = "aws_db_instance"
+	ResourceTypeAwsEip                         = "aws_eip"
+	ResourceTypeAwsElasticacheReplicationGroup = "aws_elasticache_replication_group"
+
+	DataSourceTypeAwsIamPolicyDocument = "aws_iam_policy_document"
+
+	ResourceTypeAwsIamRole                     = "aws_iam_role"
+	ResourceTypeAwsIamRolePolicy               = "aws_iam_role_policy"
+	ResourceTypeAwsIamRolePolicyAttachment     = "aws_iam_role_policy_attachment"
+	ResourceTypeAwsNetworkAcl                  = "aws_network_acl"
+	ResourceTypeAwsNetworkAclRule              = "aws_network_acl_rule"
+	ResourceTypeAwsRoute                       = "aws_route"
+	ResourceTypeAwsRouteTable                  = "aws_route_table"
+	ResourceTypeAwsSecurityGroup               = "aws_security_group"
+	ResourceTypeAwsVpcSecurityGroupEgressRule  = "aws_vpc_security_group_egress_rule"
+	ResourceTypeAwsVpcSecurityGroupIngressRule = "aws_vpc_security_group_ingress_rule"
+
+	ResourceTypeAwsSecretsManagerSecret         = "aws_secretsmanager_secret"
+	ResourceTypeAwsSecretsManagerSecretRotation = "aws_secretsmanager_secret_rotation"
+
 	ResourceTypeAwsS3Bucket                                 = "aws_s3_bucket"
 	ResourceTypeAwsS3BucketAccelerateConfiguration Resource = iota
 	ResourceTypeAwsS3BucketAcl
@@ -33,7 +74,9 @@ const (
 	ResourceTypeAwsS3BucketLifecycleConfiguration
 	ResourceTypeAwsS3BucketLogging
 	ResourceTypeAwsS3BucketObjectLockConfiguration
+	ResourceTypeAwsS3BucketOwnershipControls
 	ResourceTypeAwsS3BucketPolicy
+	ResourceTypeAwsS3BucketPublicAccessBlock
 	ResourceTypeAwsS3BucketReplicationConfiguration
 	ResourceTypeAwsS3BucketRequestPaymentConfiguration
 	ResourceTypeAwsS3BucketServerSideEncryptionConfiguration
@@ -55,8 +98,12 @@ func (r Resource) String() string {
 		return fmt.Sprintf("%s_%s", ResourceTypeAwsS3Bucket, Logging)
 	case ResourceTypeAwsS3BucketObjectLockConfiguration:
 		return fmt.Sprintf("%s_%s", ResourceTypeAwsS3Bucket, ObjectLockConfiguration)
+	case ResourceTypeAwsS3BucketOwnershipControls:
+		return fmt.Sprintf("%s_%s", ResourceTypeAwsS3Bucket, OwnershipControls)
 	case ResourceTypeAwsS3BucketPolicy:
 		return fmt.Sprintf("%s_%s", ResourceTypeAwsS3Bucket, Policy)
+	case ResourceTypeAwsS3BucketPublicAccessBlock:
+		return fmt.Sprintf("%s_%s", ResourceTypeAwsS3Bucket, PublicAccessBlock)
 	case ResourceTypeAwsS3BucketReplicationConfiguration:
 		return fmt.Sprintf("%s_%s", ResourceTypeAwsS3Bucket, ReplicationConfiguration)
 	case ResourceTypeAwsS3BucketRequestPaymentConfiguration:
@@ -70,12 +117,3 @@ func (r Resource) String() string {
 	}
 	return "unknown"
 }
-
-func init() {
-	ResourceMap = make(map[string]string)
-	ResourceMap["acceleration_status"] = ResourceTypeAwsS3BucketAccelerateConfiguration.String()
-	ResourceMap["acl"] = ResourceTypeAwsS3BucketAcl.String()
-	ResourceMap["grant"] = ResourceTypeAwsS3BucketAcl.String()
-	ResourceMap["policy"] = ResourceTypeAwsS3BucketPolicy.String()
-	ResourceMap["request_payer"] = ResourceTypeAwsS3BucketRequestPaymentConfiguration.String()
-}
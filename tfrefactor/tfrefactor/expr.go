@@ -0,0 +1,255 @@
+package tfrefactor
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// literalValue returns the value of an attribute's expression if it can be evaluated
+// statically i.e. without any variables or functions in scope.
+func literalValue(attr *hclwrite.Attribute) (cty.Value, bool) {
+	if attr == nil {
+		return cty.NilVal, false
+	}
+
+	expr, diags := hclsyntax.ParseExpression(attr.Expr().BuildTokens(nil).Bytes(), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.NilVal, false
+	}
+
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() || !v.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+
+	return v, true
+}
+
+// literalString returns the value of an attribute's expression if it is a literal string.
+func literalString(attr *hclwrite.Attribute) (string, bool) {
+	v, ok := literalValue(attr)
+	if !ok || v.IsNull() || v.Type() != cty.String {
+		return "", false
+	}
+
+	return v.AsString(), true
+}
+
+// appendTodoComment appends a "# TODO: ..." comment line to the given body.
+func appendTodoComment(body *hclwrite.Body, msg string) {
+	body.AppendUnstructuredTokens(hclwrite.Tokens{
+		{
+			Type:  hclsyntax.TokenComment,
+			Bytes: []byte("# TODO: " + msg + "\n"),
+		},
+	})
+}
+
+// objectItem is a single key/value pair of an object constructor expression.
+// The value is kept as its source text so that it can be written back as-is.
+type objectItem struct {
+	Key   string
+	Value string
+}
+
+// objectItems returns the items of an attribute's expression if it is an object constructor
+// e.g. { key = "value" } with static keys. Values may be any expression.
+func objectItems(attr *hclwrite.Attribute) ([]objectItem, bool) {
+	if attr == nil {
+		return nil, false
+	}
+
+	src := attr.Expr().BuildTokens(nil).Bytes()
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, false
+	}
+
+	objExpr, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return nil, false
+	}
+
+	return objectConsItems(objExpr, src)
+}
+
+// objectListItems returns the items of each element of an attribute's expression if it is a tuple
+// constructor of object constructors e.g. [{ key = "a" }, { key = "b" }] with static keys.
+func objectListItems(attr *hclwrite.Attribute) ([][]objectItem, bool) {
+	if attr == nil {
+		return nil, false
+	}
+
+	src := attr.Expr().BuildTokens(nil).Bytes()
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, false
+	}
+
+	tupleExpr, ok := expr.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		return nil, false
+	}
+
+	elems := make([][]objectItem, 0, len(tupleExpr.Exprs))
+	for _, e := range tupleExpr.Exprs {
+		objExpr, ok := e.(*hclsyntax.ObjectConsExpr)
+		if !ok {
+			return nil, false
+		}
+
+		items, ok := objectConsItems(objExpr, src)
+		if !ok {
+			return nil, false
+		}
+		elems = append(elems, items)
+	}
+
+	return elems, true
+}
+
+// objectConsItems returns the items of an object constructor expression parsed from src.
+func objectConsItems(objExpr *hclsyntax.ObjectConsExpr, src []byte) ([]objectItem, bool) {
+	items := make([]objectItem, 0, len(objExpr.Items))
+	for _, item := range objExpr.Items {
+		key := hcl.ExprAsKeyword(item.KeyExpr)
+		if key == "" {
+			v, diags := item.KeyExpr.Value(nil)
+			if diags.HasErrors() || v.IsNull() || !v.IsKnown() || v.Type() != cty.String {
+				return nil, false
+			}
+			key = v.AsString()
+		}
+
+		items = append(items, objectItem{
+			Key:   key,
+			Value: string(item.ValueExpr.Range().SliceBytes(src)),
+		})
+	}
+
+	return items, true
+}
+
+// literalStringList returns the elements of an attribute's expression if it is a literal list of strings.
+func literalStringList(attr *hclwrite.Attribute) ([]string, bool) {
+	v, ok := literalValue(attr)
+	if !ok || v.IsNull() || !(v.Type().IsTupleType() || v.Type().IsListType() || v.Type().IsSetType()) {
+		return nil, false
+	}
+
+	var values []string
+	for it := v.ElementIterator(); it.Next(); {
+		_, ev := it.Element()
+		if ev.IsNull() || ev.Type() != cty.String {
+			return nil, false
+		}
+		values = append(values, ev.AsString())
+	}
+
+	return values, true
+}
+
+// renameAttribute renames an attribute of the given body in place, preserving its position and comments.
+// It returns nil if the attribute does not exist or an attribute with the new name already exists.
+func renameAttribute(body *hclwrite.Body, name, newName string) *hclwrite.Attribute {
+	attr := body.GetAttribute(name)
+	if attr == nil || body.GetAttribute(newName) != nil {
+		return nil
+	}
+
+	for _, t := range attr.BuildTokens(nil) {
+		if t.Type == hclsyntax.TokenIdent && string(t.Bytes) == name {
+			t.Bytes = []byte(newName)
+			break
+		}
+	}
+
+	return attr
+}
+
+// listTokens wraps the given expression tokens in a list e.g. [expr].
+func listTokens(tokens hclwrite.Tokens) hclwrite.Tokens {
+	list := hclwrite.Tokens{{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")}}
+	list = append(list, tokens...)
+	return append(list, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+}
+
+// resourceNameSuffix returns a value e.g. a CIDR block as a valid resource name suffix
+// e.g. "10_0_0_0_16" for "10.0.0.0/16".
+func resourceNameSuffix(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteRune('_')
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// sortedAttributeNames returns the names of the attributes of the given body in alphabetical order.
+func sortedAttributeNames(body *hclwrite.Body) []string {
+	names := make([]string, 0, len(body.Attributes()))
+	for k := range body.Attributes() {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// tupleItems returns the source text of each element of an attribute's expression
+// if it is a tuple constructor e.g. ["a", var.b].
+func tupleItems(attr *hclwrite.Attribute) ([]string, bool) {
+	if attr == nil {
+		return nil, false
+	}
+
+	src := attr.Expr().BuildTokens(nil).Bytes()
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, false
+	}
+
+	tupleExpr, ok := expr.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		return nil, false
+	}
+
+	items := make([]string, 0, len(tupleExpr.Exprs))
+	for _, e := range tupleExpr.Exprs {
+		items = append(items, string(e.Range().SliceBytes(src)))
+	}
+
+	return items, true
+}
+
+// referencesInstance returns whether an attribute's expression references the instance
+// of its resource i.e. count.index or each.key and each.value.
+func referencesInstance(attr *hclwrite.Attribute) bool {
+	if attr == nil {
+		return false
+	}
+
+	expr, diags := hclsyntax.ParseExpression(attr.Expr().BuildTokens(nil).Bytes(), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return true
+	}
+
+	for _, traversal := range expr.Variables() {
+		if root := traversal.RootName(); root == "count" || root == "each" {
+			return true
+		}
+	}
+
+	return false
+}
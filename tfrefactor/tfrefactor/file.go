@@ -20,39 +20,61 @@ import (
 // We use an afero filesystem here for testing.
 func MigrateFile(fs afero.Fs, filename string, o Option) error {
 	log.Printf("[DEBUG] check file: %s", filename)
+	o = o.ForDir(filepath.Dir(filename))
+	o.modules = o.ModuleAddresses[filepath.Clean(filepath.Dir(filename))]
+
+	if o.Module == nil {
+		module, err := LoadModule(fs, filepath.Dir(filename))
+		if err != nil {
+			return err
+		}
+		o.Module = module
+	}
+
 	r, err := fs.Open(filename)
 	if err != nil {
 		return fmt.Errorf("[ERROR] failed to open file: %s", err)
 	}
 	defer r.Close()
 
+	// Only the generate blocks of .hcl files e.g. terragrunt.hcl contain configurations to migrate
+	migrate := MigrateHCL
+	outputFilename := strings.Replace(filename, ".tf", "_migrated.tf", 1)
+	csvFilename := strings.Replace(strings.TrimSuffix(filename, ".json"), ".tf", "_new_resources.csv", 1)
+	if isGenerateFile(filename) {
+		migrate = MigrateGenerateBlocks
+		outputFilename = strings.TrimSuffix(filename, ".hcl") + "_migrated.hcl"
+		csvFilename = strings.TrimSuffix(filename, ".hcl") + "_new_resources.csv"
+	}
+
 	w := &bytes.Buffer{}
-	newResourceNames, err := MigrateHCL(r, w, filename, o)
+	newResourceNames, err := migrate(r, w, filename, o)
 	if err != nil {
 		return err
 	}
 
 	// Write contents to destination file if migrations occurred.
-	if len(newResourceNames) == 0 {
+	if w.Len() == 0 {
 		log.Printf("[DEBUG] no migration file to create for %s", filename)
 		return nil
 	}
 
-	outputFilename := strings.Replace(filename, ".tf", "_migrated.tf", 1)
 	log.Printf("[INFO] new file: %s", outputFilename)
-	migrated := w.Bytes()
+	result := w.Bytes()
 	// We should be able to choose whether to format output or not.
 	// However, the current implementation of (*hclwrite.Body).SetAttributeValue()
 	// does not seem to preserve an original SpaceBefore value of attribute.
-	// So, we need to format output here.
-	result := hclwrite.Format(migrated)
+	// So, we need to format output here. JSON output is already formatted.
+	if !isJSONFile(filename) {
+		result = hclwrite.Format(result)
+	}
 	if err = afero.WriteFile(fs, outputFilename, result, 0644); err != nil {
 		return fmt.Errorf("failed to write file: %s", err)
 	}
 
 	// Write migrations to csv file
 	if o.Csv {
-		newFile, err := os.Create(strings.Replace(filename, ".tf", "_new_resources.csv", 1))
+		newFile, err := os.Create(csvFilename)
 		log.Printf("[INFO] new file: %s", newFile.Name())
 		if err != nil {
 			return fmt.Errorf("[ERROR] error creating (%s): %s", newFile.Name(), err)
@@ -73,7 +95,8 @@ func MigrateFile(fs afero.Fs, filename string, o Option) error {
 // MigrateDir migrates resources for files in a given directory.
 // If a recursive flag is true, it checks and migrates recursively.
 // skip hidden directories such as .terraform or .git.
-// It also skips a file without .tf extension.
+// It also skips a file without .tf or .tf.json extension, unless it is an .hcl file
+// and the hcl files flag is true.
 func MigrateDir(fs afero.Fs, dirname string, o Option) error {
 	log.Printf("[DEBUG] check dir: %s", dirname)
 	dir, err := afero.ReadDir(fs, dirname)
@@ -81,6 +104,12 @@ func MigrateDir(fs afero.Fs, dirname string, o Option) error {
 		return fmt.Errorf("failed to open dir: %s", err)
 	}
 
+	// Each directory is a separate module
+	o.Module, err = LoadModule(fs, dirname)
+	if err != nil {
+		return err
+	}
+
 	for _, entry := range dir {
 		path := filepath.Join(dirname, entry.Name())
 
@@ -110,8 +139,8 @@ func MigrateDir(fs afero.Fs, dirname string, o Option) error {
 		}
 
 		// if an entry is a file
-		if filepath.Ext(entry.Name()) != ".tf" {
-			// skip a file without .tf extension.
+		if !isConfigFile(entry.Name()) && !(o.HclFiles && isGenerateFile(entry.Name())) {
+			// skip a file without .tf or .tf.json extension, or .hcl extension if the hcl files flag is true.
 			continue
 		}
 
@@ -130,6 +159,25 @@ func MigrateFileOrDir(fs afero.Fs, path string, o Option) error {
 		return fmt.Errorf("failed to open path: %s", err)
 	}
 
+	// Migrator plugins are started once and stopped at the end of the run
+	if o.plugins == nil {
+		o.plugins = newPluginClients()
+		defer o.plugins.kill()
+	}
+
+	// Resources can be matched by their address in modules called from the path
+	if o.ModuleAddresses == nil {
+		root := path
+		if !isDir {
+			root = filepath.Dir(path)
+		}
+
+		o.ModuleAddresses, err = LoadModuleAddresses(fs, root)
+		if err != nil {
+			return err
+		}
+	}
+
 	if isDir {
 		// if an entry is a directory
 		return MigrateDir(fs, path, o)
@@ -138,3 +186,8 @@ func MigrateFileOrDir(fs afero.Fs, path string, o Option) error {
 	// if an entry is a file
 	return MigrateFile(fs, path, o)
 }
+
+// isConfigFile returns whether a file is a Terraform configuration file in the native or JSON syntax.
+func isConfigFile(filename string) bool {
+	return filepath.Ext(filename) == ".tf" || isJSONFile(filename)
+}
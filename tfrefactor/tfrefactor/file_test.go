@@ -138,6 +138,1581 @@ resource "aws_s3_bucket_acl" "test_acl" {
   bucket = aws_s3_bucket.test.id
   acl    = "private"
 }
+`,
+		},
+		{
+			filename: "lifecycle_date.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  lifecycle_rule {
+    enabled = true
+    expiration {
+      date = "2023-01-13"
+    }
+  }
+
+  lifecycle_rule {
+    enabled = true
+    transition {
+      date = var.transition_date
+    }
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			expectedMigrationFilename: "lifecycle_date_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+
+}
+
+resource "aws_s3_bucket_lifecycle_configuration" "test_lifecycle_configuration" {
+  bucket = aws_s3_bucket.test.id
+  rule {
+    status = "Enabled"
+    filter {
+    }
+    expiration {
+      date = "2023-01-13T00:00:00Z"
+    }
+  }
+  rule {
+    status = "Enabled"
+    filter {
+    }
+    transition {
+      date = var.transition_date
+      # TODO: Ensure 'date' is in RFC3339 format e.g. 2023-01-13T00:00:00Z
+    }
+  }
+}
+`,
+		},
+		{
+			filename: "lifecycle_filter.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  lifecycle_rule {
+    enabled = true
+    tags = {
+      env = "prod"
+    }
+  }
+
+  lifecycle_rule {
+    enabled = true
+    prefix  = "logs/"
+    tags = {
+      env = "prod"
+    }
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			expectedMigrationFilename: "lifecycle_filter_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+
+}
+
+resource "aws_s3_bucket_lifecycle_configuration" "test_lifecycle_configuration" {
+  bucket = aws_s3_bucket.test.id
+  rule {
+    status = "Enabled"
+    filter {
+      tag {
+        key   = "env"
+        value = "prod"
+      }
+    }
+  }
+  rule {
+    status = "Enabled"
+    filter {
+      and {
+        prefix = "logs/"
+        tags = {
+          env = "prod"
+        }
+      }
+    }
+  }
+}
+`,
+		},
+		{
+			filename: "replication_v2.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  versioning {
+    enabled = true
+  }
+
+  replication_configuration {
+    role = aws_iam_role.test.arn
+    rules {
+      priority = 1
+      prefix   = "logs/"
+      destination {
+        bucket = aws_s3_bucket.destination.arn
+      }
+    }
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			expectedMigrationFilename: "replication_v2_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+
+}
+
+resource "aws_s3_bucket_versioning" "test_versioning" {
+  bucket = aws_s3_bucket.test.id
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+resource "aws_s3_bucket_replication_configuration" "test_replication_configuration" {
+  bucket = aws_s3_bucket.test.id
+  role   = aws_iam_role.test.arn
+  rule {
+    priority = 1
+    destination {
+      bucket = aws_s3_bucket.destination.arn
+    }
+    filter {
+      prefix = "logs/"
+    }
+    delete_marker_replication {
+      status = "Disabled"
+    }
+  }
+
+  depends_on = [aws_s3_bucket_versioning.test_versioning]
+}
+`,
+		},
+		{
+			filename: "replication_filter.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  replication_configuration {
+    role = aws_iam_role.test.arn
+    rules {
+      prefix = "logs/"
+      status = "Enabled"
+
+      filter {
+        tags = {
+          Replicate = "true"
+        }
+      }
+
+      destination {
+        bucket = aws_s3_bucket.destination.arn
+      }
+    }
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			expectedMigrationFilename: "replication_filter_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+}
+
+resource "aws_s3_bucket_replication_configuration" "test_replication_configuration" {
+  bucket = aws_s3_bucket.test.id
+  role   = aws_iam_role.test.arn
+  rule {
+    status = "Enabled"
+    filter {
+      and {
+        prefix = "logs/"
+        tags = {
+          Replicate = "true"
+        }
+      }
+    }
+    destination {
+      bucket = aws_s3_bucket.destination.arn
+    }
+  }
+}
+`,
+		},
+		{
+			filename: "object_lock_enabled.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  object_lock_configuration {
+    object_lock_enabled = "Enabled"
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			expectedMigrationFilename: "object_lock_enabled_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  object_lock_enabled = true
+}
+`,
+		},
+		{
+			filename: "website_redirect.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  website {
+    redirect_all_requests_to = "https://example.com"
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			expectedMigrationFilename: "website_redirect_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+}
+
+resource "aws_s3_bucket_website_configuration" "test_website_configuration" {
+  bucket = aws_s3_bucket.test.id
+  redirect_all_requests_to {
+    host_name = "example.com"
+    protocol  = "https"
+  }
+}
+`,
+		},
+		{
+			filename: "website_redirect_path.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  website {
+    redirect_all_requests_to = "https://example.com/docs?lang=en"
+  }
+}
+
+resource "aws_s3_bucket" "host" {
+  bucket = "tf-acc-test-5678"
+
+  website {
+    redirect_all_requests_to = "example.com/"
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			expectedMigrationFilename: "website_redirect_path_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+}
+
+resource "aws_s3_bucket" "host" {
+  bucket = "tf-acc-test-5678"
+
+}
+
+resource "aws_s3_bucket_website_configuration" "test_website_configuration" {
+  bucket = aws_s3_bucket.test.id
+  redirect_all_requests_to {
+    host_name = "example.com"
+    protocol  = "https"
+    # TODO: Requests were redirected to "https://example.com/docs?lang=en"; use 'routing_rule' blocks to redirect to a path, query or fragment
+  }
+}
+
+resource "aws_s3_bucket_website_configuration" "host_website_configuration" {
+  bucket = aws_s3_bucket.host.id
+  redirect_all_requests_to {
+    host_name = "example.com"
+  }
+}
+`,
+		},
+		{
+			filename: "website_routing_rules.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  website {
+    routing_rules = var.routing_rules
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			expectedMigrationFilename: "website_routing_rules_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+}
+
+resource "aws_s3_bucket_website_configuration" "test_website_configuration" {
+  bucket = aws_s3_bucket.test.id
+  dynamic "routing_rule" {
+    for_each = jsondecode(var.routing_rules)
+
+    content {
+      dynamic "condition" {
+        for_each = lookup(routing_rule.value, "Condition", null) == null ? [] : [routing_rule.value.Condition]
+
+        content {
+          http_error_code_returned_equals = lookup(condition.value, "HttpErrorCodeReturnedEquals", null)
+          key_prefix_equals               = lookup(condition.value, "KeyPrefixEquals", null)
+        }
+      }
+
+      redirect {
+        host_name               = lookup(routing_rule.value.Redirect, "HostName", null)
+        http_redirect_code      = lookup(routing_rule.value.Redirect, "HttpRedirectCode", null)
+        protocol                = lookup(routing_rule.value.Redirect, "Protocol", null)
+        replace_key_prefix_with = lookup(routing_rule.value.Redirect, "ReplaceKeyPrefixWith", null)
+        replace_key_with        = lookup(routing_rule.value.Redirect, "ReplaceKeyWith", null)
+      }
+    }
+  }
+}
+`,
+		},
+		{
+			filename: "grant_permissions.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  grant {
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+    permissions = ["READ_ACP", "WRITE"]
+  }
+
+  grant {
+    id          = data.aws_canonical_user_id.current.id
+    permissions = var.permissions
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			expectedMigrationFilename: "grant_permissions_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+
+}
+
+resource "aws_s3_bucket_acl" "test_acl" {
+  bucket = aws_s3_bucket.test.id
+  access_control_policy {
+    grant {
+      grantee {
+        uri = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "READ_ACP"
+    }
+    grant {
+      grantee {
+        uri = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "WRITE"
+    }
+    dynamic "grant" {
+      for_each = toset(var.permissions)
+
+      content {
+        grantee {
+          id = data.aws_canonical_user_id.current.id
+        }
+        permission = grant.value
+      }
+    }
+  }
+}
+`,
+		},
+		{
+			filename: "ownership_controls.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+  acl    = "public-read"
+}
+`,
+			o: Option{
+				MigratorType:      "resource",
+				ResourceType:      ResourceTypeAwsS3Bucket,
+				OwnershipControls: true,
+			},
+			expectedMigrationFilename: "ownership_controls_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+}
+
+resource "aws_s3_bucket_acl" "test_acl" {
+  bucket = aws_s3_bucket.test.id
+  acl    = "public-read"
+
+  depends_on = [aws_s3_bucket_ownership_controls.test_ownership_controls, aws_s3_bucket_public_access_block.test_public_access_block]
+}
+
+resource "aws_s3_bucket_ownership_controls" "test_ownership_controls" {
+  bucket = aws_s3_bucket.test.id
+  rule {
+    object_ownership = "BucketOwnerPreferred"
+  }
+}
+
+resource "aws_s3_bucket_public_access_block" "test_public_access_block" {
+  bucket = aws_s3_bucket.test.id
+
+  block_public_acls  = false
+  ignore_public_acls = false
+}
+`,
+		},
+		{
+			filename: "ownership_controls_log_delivery.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+  acl    = "log-delivery-write"
+}
+`,
+			o: Option{
+				MigratorType:      "resource",
+				ResourceType:      ResourceTypeAwsS3Bucket,
+				OwnershipControls: true,
+			},
+			expectedMigrationFilename: "ownership_controls_log_delivery_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+}
+
+resource "aws_s3_bucket_acl" "test_acl" {
+  bucket = aws_s3_bucket.test.id
+  acl    = "log-delivery-write"
+
+  depends_on = [aws_s3_bucket_ownership_controls.test_ownership_controls]
+}
+
+resource "aws_s3_bucket_ownership_controls" "test_ownership_controls" {
+  bucket = aws_s3_bucket.test.id
+  rule {
+    object_ownership = "BucketOwnerPreferred"
+  }
+}
+`,
+		},
+		{
+			filename: "ownership_controls_grants.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+  grant {
+    id          = data.aws_canonical_user_id.current.id
+    type        = "CanonicalUser"
+    permissions = ["FULL_CONTROL"]
+  }
+
+  grant {
+    type        = "Group"
+    uri         = "http://acs.amazonaws.com/groups/global/AllUsers"
+    permissions = ["READ"]
+  }
+}
+`,
+			o: Option{
+				MigratorType:      "resource",
+				ResourceType:      ResourceTypeAwsS3Bucket,
+				OwnershipControls: true,
+			},
+			expectedMigrationFilename: "ownership_controls_grants_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+
+
+}
+
+resource "aws_s3_bucket_acl" "test_acl" {
+  bucket = aws_s3_bucket.test.id
+  access_control_policy {
+    grant {
+      grantee {
+        id   = data.aws_canonical_user_id.current.id
+        type = "CanonicalUser"
+      }
+      permission = "FULL_CONTROL"
+    }
+    grant {
+      grantee {
+        type = "Group"
+        uri  = "http://acs.amazonaws.com/groups/global/AllUsers"
+      }
+      permission = "READ"
+    }
+  }
+
+  depends_on = [aws_s3_bucket_ownership_controls.test_ownership_controls, aws_s3_bucket_public_access_block.test_public_access_block]
+}
+
+resource "aws_s3_bucket_ownership_controls" "test_ownership_controls" {
+  bucket = aws_s3_bucket.test.id
+  rule {
+    object_ownership = "BucketOwnerPreferred"
+  }
+}
+
+resource "aws_s3_bucket_public_access_block" "test_public_access_block" {
+  bucket = aws_s3_bucket.test.id
+
+  block_public_acls  = false
+  ignore_public_acls = false
+}
+`,
+		},
+		{
+			filename: "ownership_controls_private.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+  acl    = "private"
+}
+`,
+			o: Option{
+				MigratorType:      "resource",
+				ResourceType:      ResourceTypeAwsS3Bucket,
+				OwnershipControls: true,
+			},
+			expectedMigrationFilename: "ownership_controls_private_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+}
+
+resource "aws_s3_bucket_acl" "test_acl" {
+  bucket = aws_s3_bucket.test.id
+  acl    = "private"
+}
+`,
+		},
+		{
+			filename: "ownership_controls_variable.tf",
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+  acl    = var.acl
+}
+`,
+			o: Option{
+				MigratorType:      "resource",
+				ResourceType:      ResourceTypeAwsS3Bucket,
+				OwnershipControls: true,
+			},
+			expectedMigrationFilename: "ownership_controls_variable_migrated.tf",
+			want: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+}
+
+resource "aws_s3_bucket_acl" "test_acl" {
+  bucket = aws_s3_bucket.test.id
+  acl    = var.acl
+  # TODO: Add 'aws_s3_bucket_ownership_controls' and 'aws_s3_bucket_public_access_block' resources if the 'acl' is not private
+}
+`,
+		},
+		{
+			filename: "s3_bucket_object.tf",
+			src: `
+resource "aws_s3_bucket_object" "test" {
+  bucket = aws_s3_bucket.test.id
+  key    = "index.html"
+}
+
+data "aws_s3_bucket_objects" "test" {
+  bucket = aws_s3_bucket.test.id
+}
+
+output "etag" {
+  value = "${aws_s3_bucket_object.test.etag}-${length(data.aws_s3_bucket_objects.test.keys)}"
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3BucketObject,
+			},
+			expectedMigrationFilename: "s3_bucket_object_migrated.tf",
+			want: `
+resource "aws_s3_object" "test" {
+  bucket = aws_s3_bucket.test.id
+  key    = "index.html"
+}
+
+data "aws_s3_objects" "test" {
+  bucket = aws_s3_bucket.test.id
+}
+
+output "etag" {
+  value = "${aws_s3_object.test.etag}-${length(data.aws_s3_objects.test.keys)}"
+}
+
+moved {
+  from = aws_s3_bucket_object.test
+  to   = aws_s3_object.test
+}
+`,
+		},
+		{
+			filename: "s3_bucket_object_v4.tf",
+			src: `
+resource "aws_s3_bucket_object" "test" {
+  bucket = aws_s3_bucket.test.id
+  key    = "index.html"
+}
+`,
+			o: Option{
+				MigratorType:    "resource",
+				ResourceType:    ResourceTypeAwsS3BucketObject,
+				ProviderVersion: "~> 4.0",
+			},
+			expectedMigrationFilename: "s3_bucket_object_v4_migrated.tf",
+			want: `
+resource "aws_s3_object" "test" {
+  bucket = aws_s3_bucket.test.id
+  key    = "index.html"
+}
+
+moved {
+  from = aws_s3_bucket_object.test
+  to   = aws_s3_object.test
+  # TODO: Replace with 'terraform state rm aws_s3_bucket_object.test' and 'terraform import aws_s3_object.test <bucket>/<key>', as provider version ~> 4.0 does not support this move
+}
+`,
+		},
+		{
+			filename: "s3_bucket_object_references.tf",
+			src: `
+output "ids" {
+  value = aws_s3_bucket_object.test[*].id
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3BucketObject,
+			},
+			expectedMigrationFilename: "s3_bucket_object_references_migrated.tf",
+			want: `
+output "ids" {
+  value = aws_s3_object.test[*].id
+}
+`,
+		},
+		{
+			filename: "secretsmanager_secret.tf",
+			src: `
+resource "aws_secretsmanager_secret" "test" {
+  name                = "test"
+  rotation_lambda_arn = aws_lambda_function.test.arn
+
+  rotation_rules {
+    automatically_after_days = 7
+  }
+}
+
+resource "aws_secretsmanager_secret" "counted" {
+  count               = 2
+  name                = "counted-${count.index}"
+  rotation_enabled    = true
+  rotation_lambda_arn = aws_lambda_function.test.arn
+
+  rotation_rules {
+    automatically_after_days = 30
+  }
+}
+
+resource "aws_secretsmanager_secret" "each" {
+  for_each            = var.secrets
+  name                = each.key
+  rotation_lambda_arn = each.value.lambda_arn
+}
+
+resource "aws_secretsmanager_secret" "disabled" {
+  name             = "disabled"
+  rotation_enabled = false
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsSecretsManagerSecret,
+			},
+			expectedMigrationFilename: "secretsmanager_secret_migrated.tf",
+			want: `
+resource "aws_secretsmanager_secret" "test" {
+  name = "test"
+
+}
+
+resource "aws_secretsmanager_secret" "counted" {
+  count = 2
+  name  = "counted-${count.index}"
+
+}
+
+resource "aws_secretsmanager_secret" "each" {
+  for_each = var.secrets
+  name     = each.key
+}
+
+resource "aws_secretsmanager_secret" "disabled" {
+  name = "disabled"
+}
+
+resource "aws_secretsmanager_secret_rotation" "test_rotation" {
+  secret_id           = aws_secretsmanager_secret.test.id
+  rotation_lambda_arn = aws_lambda_function.test.arn
+  rotation_rules {
+    automatically_after_days = 7
+  }
+}
+
+resource "aws_secretsmanager_secret_rotation" "counted_rotation" {
+  count = 2
+
+  secret_id           = aws_secretsmanager_secret.counted[count.index].id
+  rotation_lambda_arn = aws_lambda_function.test.arn
+  rotation_rules {
+    automatically_after_days = 30
+  }
+}
+
+resource "aws_secretsmanager_secret_rotation" "each_rotation" {
+  for_each = var.secrets
+
+  secret_id           = aws_secretsmanager_secret.each[each.key].id
+  rotation_lambda_arn = each.value.lambda_arn
+  # TODO: Add your 'rotation_rules' configuration
+}
+`,
+		},
+		{
+			filename: "secretsmanager_secret_condition.tf",
+			src: `
+resource "aws_secretsmanager_secret" "test" {
+  name                = "test"
+  rotation_enabled    = var.enable_rotation
+  rotation_lambda_arn = aws_lambda_function.test.arn
+
+  rotation_rules {
+    automatically_after_days = 7
+  }
+}
+
+resource "aws_secretsmanager_secret" "counted" {
+  count               = 2
+  name                = "counted-${count.index}"
+  rotation_enabled    = var.environment == "prod"
+  rotation_lambda_arn = aws_lambda_function.test.arn
+}
+
+resource "aws_secretsmanager_secret" "each" {
+  for_each            = var.secrets
+  name                = each.key
+  rotation_enabled    = var.enable_rotation
+  rotation_lambda_arn = each.value.lambda_arn
+}
+
+resource "aws_secretsmanager_secret" "instance" {
+  for_each            = var.secrets
+  name                = each.key
+  rotation_enabled    = each.value.rotate
+  rotation_lambda_arn = each.value.lambda_arn
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsSecretsManagerSecret,
+			},
+			expectedMigrationFilename: "secretsmanager_secret_condition_migrated.tf",
+			want: `
+resource "aws_secretsmanager_secret" "test" {
+  name = "test"
+
+}
+
+resource "aws_secretsmanager_secret" "counted" {
+  count = 2
+  name  = "counted-${count.index}"
+}
+
+resource "aws_secretsmanager_secret" "each" {
+  for_each = var.secrets
+  name     = each.key
+}
+
+resource "aws_secretsmanager_secret" "instance" {
+  for_each            = var.secrets
+  name                = each.key
+  rotation_enabled    = each.value.rotate
+  rotation_lambda_arn = each.value.lambda_arn
+  # TODO: Migrate 'rotation_enabled', 'rotation_lambda_arn' and 'rotation_rules' to a 'aws_secretsmanager_secret_rotation' resource created only where rotation is enabled
+}
+
+resource "aws_secretsmanager_secret_rotation" "test_rotation" {
+  count = var.enable_rotation ? 1 : 0
+
+  secret_id           = aws_secretsmanager_secret.test.id
+  rotation_lambda_arn = aws_lambda_function.test.arn
+  rotation_rules {
+    automatically_after_days = 7
+  }
+}
+
+resource "aws_secretsmanager_secret_rotation" "counted_rotation" {
+  count = (var.environment == "prod") ? 2 : 0
+
+  secret_id           = aws_secretsmanager_secret.counted[count.index].id
+  rotation_lambda_arn = aws_lambda_function.test.arn
+  # TODO: Add your 'rotation_rules' configuration
+}
+
+resource "aws_secretsmanager_secret_rotation" "each_rotation" {
+  for_each = { for k, v in var.secrets : k => v if var.enable_rotation }
+
+  secret_id           = aws_secretsmanager_secret.each[each.key].id
+  rotation_lambda_arn = each.value.lambda_arn
+  # TODO: Add your 'rotation_rules' configuration
+}
+`,
+		},
+		{
+			filename: "secretsmanager_secret_ignore.tf",
+			src: `
+resource "aws_secretsmanager_secret" "test" {
+  name                = "test"
+  rotation_lambda_arn = aws_lambda_function.test.arn
+
+  rotation_rules {
+    automatically_after_days = 7
+  }
+}
+
+resource "aws_secretsmanager_secret" "disabled" {
+  name             = "disabled"
+  rotation_enabled = false
+
+  rotation_rules {
+    automatically_after_days = 7
+  }
+}
+`,
+			o: Option{
+				MigratorType:    "resource",
+				ResourceType:    ResourceTypeAwsSecretsManagerSecret,
+				IgnoreArguments: []string{RotationLambdaArn},
+			},
+			expectedMigrationFilename: "secretsmanager_secret_ignore_migrated.tf",
+			want: `
+resource "aws_secretsmanager_secret" "test" {
+  name                = "test"
+  rotation_lambda_arn = aws_lambda_function.test.arn
+
+}
+
+resource "aws_secretsmanager_secret" "disabled" {
+  name = "disabled"
+
+  rotation_rules {
+    automatically_after_days = 7
+  }
+  # TODO: Rotation is disabled; remove 'rotation_lambda_arn' and 'rotation_rules' or migrate them to a 'aws_secretsmanager_secret_rotation' resource
+}
+
+resource "aws_secretsmanager_secret_rotation" "test_rotation" {
+  secret_id = aws_secretsmanager_secret.test.id
+  rotation_rules {
+    automatically_after_days = 7
+  }
+}
+`,
+		},
+		{
+			filename: "provider.tf",
+			src: `
+provider "aws" {
+  region                  = "us-west-2"
+  s3_force_path_style     = true
+  shared_credentials_file = "~/.aws/credentials"
+  skip_get_ec2_platforms  = true
+}
+
+provider "aws" {
+  alias = "east"
+
+  assume_role {
+    role_arn         = var.role_arn
+    duration_seconds = 3600
+  }
+}
+
+provider "aws" {
+  alias = "west"
+
+  assume_role {
+    duration_seconds = var.duration
+  }
+}
+`,
+			o: Option{
+				MigratorType:    "resource",
+				ResourceType:    ResourceTypeAwsS3Bucket,
+				ProviderVersion: "~> 4.0",
+			},
+			expectedMigrationFilename: "provider_migrated.tf",
+			want: `
+provider "aws" {
+  region                   = "us-west-2"
+  s3_use_path_style        = true
+  shared_credentials_files = ["~/.aws/credentials"]
+}
+
+provider "aws" {
+  alias = "east"
+
+  assume_role {
+    role_arn = var.role_arn
+    duration = "3600s"
+  }
+}
+
+provider "aws" {
+  alias = "west"
+
+  assume_role {
+    duration = "${var.duration}s"
+  }
+}
+`,
+		},
+		{
+			filename: "provider_v5.tf",
+			src: `
+resource "aws_eip" "test" {
+  instance = aws_instance.test.id
+  vpc      = true
+}
+
+resource "aws_eip" "conditional" {
+  vpc = var.in_vpc
+}
+
+resource "aws_db_instance" "test" {
+  name           = "mydb"
+  engine         = "mysql"
+  instance_class = "db.t3.micro"
+}
+
+resource "aws_elasticache_replication_group" "test" {
+  replication_group_id          = "test"
+  replication_group_description = "test description"
+  number_cache_clusters         = 2
+}
+
+resource "aws_elasticache_replication_group" "cluster" {
+  replication_group_id          = "cluster"
+  replication_group_description = "cluster description"
+
+  cluster_mode {
+    num_node_groups         = 2
+    replicas_per_node_group = 1
+  }
+}
+
+resource "aws_autoscaling_attachment" "test" {
+  autoscaling_group_name = aws_autoscaling_group.test.id
+  alb_target_group_arn   = aws_lb_target_group.test.arn
+}
+`,
+			o: Option{
+				MigratorType:    "resource",
+				ResourceType:    ResourceTypeAwsS3Bucket,
+				ProviderVersion: "~> 5.0",
+			},
+			expectedMigrationFilename: "provider_v5_migrated.tf",
+			want: `
+resource "aws_eip" "test" {
+  instance = aws_instance.test.id
+  domain   = "vpc"
+}
+
+resource "aws_eip" "conditional" {
+  domain = var.in_vpc ? "vpc" : "standard"
+}
+
+resource "aws_db_instance" "test" {
+  db_name        = "mydb"
+  engine         = "mysql"
+  instance_class = "db.t3.micro"
+}
+
+resource "aws_elasticache_replication_group" "test" {
+  replication_group_id = "test"
+  description          = "test description"
+  num_cache_clusters   = 2
+}
+
+resource "aws_elasticache_replication_group" "cluster" {
+  replication_group_id = "cluster"
+  description          = "cluster description"
+
+  num_node_groups         = 2
+  replicas_per_node_group = 1
+}
+
+resource "aws_autoscaling_attachment" "test" {
+  autoscaling_group_name = aws_autoscaling_group.test.id
+  lb_target_group_arn    = aws_lb_target_group.test.arn
+}
+`,
+		},
+		{
+			filename: "autoscaling_group.tf",
+			src: `
+resource "aws_autoscaling_group" "test" {
+  max_size = 1
+  min_size = 1
+
+  tags = [
+    {
+      key                 = "Name"
+      value               = "test"
+      propagate_at_launch = "true"
+    },
+    {
+      key                 = "Environment"
+      value               = var.environment
+      propagate_at_launch = false
+    },
+  ]
+}
+
+resource "aws_autoscaling_group" "dynamic" {
+  max_size = 1
+  min_size = 1
+  tags     = var.tags
+}
+`,
+			o: Option{
+				MigratorType:    "resource",
+				ResourceType:    ResourceTypeAwsS3Bucket,
+				ProviderVersion: "~> 5.0",
+			},
+			expectedMigrationFilename: "autoscaling_group_migrated.tf",
+			want: `
+resource "aws_autoscaling_group" "test" {
+  max_size = 1
+  min_size = 1
+
+  tag {
+    key                 = "Name"
+    value               = "test"
+    propagate_at_launch = true
+  }
+  tag {
+    key                 = "Environment"
+    value               = var.environment
+    propagate_at_launch = false
+  }
+}
+
+resource "aws_autoscaling_group" "dynamic" {
+  max_size = 1
+  min_size = 1
+  dynamic "tag" {
+    for_each = var.tags
+
+    content {
+      key                 = tag.value.key
+      value               = tag.value.value
+      propagate_at_launch = tag.value.propagate_at_launch
+    }
+  }
+}
+`,
+		},
+		{
+			filename: "provider_v5_ignore.tf",
+			src: `
+resource "aws_eip" "test" {
+  vpc = true
+}
+
+resource "aws_db_instance" "test" {
+  name           = "mydb"
+  engine         = "mysql"
+  instance_class = "db.t3.micro"
+}
+`,
+			o: Option{
+				MigratorType:    "resource",
+				ResourceType:    ResourceTypeAwsEip,
+				ProviderVersion: "~> 5.0",
+				IgnoreArguments: []string{"name"},
+			},
+			expectedMigrationFilename: "provider_v5_ignore_migrated.tf",
+			want: `
+resource "aws_eip" "test" {
+  domain = "vpc"
+}
+
+resource "aws_db_instance" "test" {
+  name           = "mydb"
+  engine         = "mysql"
+  instance_class = "db.t3.micro"
+}
+`,
+		},
+		{
+			filename: "iam_policy_document.tf",
+			src: `
+data "aws_iam_policy_document" "test" {
+  source_json   = data.aws_iam_policy_document.base.json
+  override_json = var.override_policy
+
+  statement {
+    actions   = ["s3:GetObject"]
+    resources = ["*"]
+  }
+}
+
+data "aws_iam_policy_document" "existing" {
+  source_json             = data.aws_iam_policy_document.base.json
+  source_policy_documents = [data.aws_iam_policy_document.other.json]
+}
+`,
+			o: Option{
+				MigratorType: "data",
+				ResourceType: DataSourceTypeAwsIamPolicyDocument,
+			},
+			expectedMigrationFilename: "iam_policy_document_migrated.tf",
+			want: `
+data "aws_iam_policy_document" "test" {
+  source_policy_documents   = [data.aws_iam_policy_document.base.json]
+  override_policy_documents = [var.override_policy]
+
+  statement {
+    actions   = ["s3:GetObject"]
+    resources = ["*"]
+  }
+}
+
+data "aws_iam_policy_document" "existing" {
+  source_json             = data.aws_iam_policy_document.base.json
+  source_policy_documents = [data.aws_iam_policy_document.other.json]
+  # TODO: Add the value of 'source_json' to 'source_policy_documents'
+}
+`,
+		},
+		{
+			filename: "security_group.tf",
+			src: `
+resource "aws_security_group" "test" {
+  name   = "test"
+  vpc_id = aws_vpc.test.id
+
+  ingress {
+    description = "TLS from VPC"
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = ["10.0.0.0/16", "10.1.0.0/16"]
+  }
+
+  ingress {
+    from_port       = 22
+    to_port         = 22
+    protocol        = "tcp"
+    security_groups = var.bastion_security_group_ids
+    self            = true
+  }
+
+  egress {
+    from_port        = 0
+    to_port          = 0
+    protocol         = "-1"
+    cidr_blocks      = ["0.0.0.0/0"]
+    ipv6_cidr_blocks = ["::/0"]
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsSecurityGroup,
+			},
+			expectedMigrationFilename: "security_group_migrated.tf",
+			want: `
+resource "aws_security_group" "test" {
+  name   = "test"
+  vpc_id = aws_vpc.test.id
+
+
+
+}
+
+resource "aws_vpc_security_group_ingress_rule" "test_ingress_0" {
+  security_group_id = aws_security_group.test.id
+  description       = "TLS from VPC"
+  ip_protocol       = "tcp"
+  from_port         = 443
+  to_port           = 443
+  cidr_ipv4         = "10.0.0.0/16"
+}
+
+resource "aws_vpc_security_group_ingress_rule" "test_ingress_1" {
+  security_group_id = aws_security_group.test.id
+  description       = "TLS from VPC"
+  ip_protocol       = "tcp"
+  from_port         = 443
+  to_port           = 443
+  cidr_ipv4         = "10.1.0.0/16"
+}
+
+resource "aws_vpc_security_group_ingress_rule" "test_ingress_2" {
+  for_each = toset(var.bastion_security_group_ids)
+
+  security_group_id            = aws_security_group.test.id
+  ip_protocol                  = "tcp"
+  from_port                    = 22
+  to_port                      = 22
+  referenced_security_group_id = each.value
+}
+
+resource "aws_vpc_security_group_ingress_rule" "test_ingress_3" {
+  security_group_id            = aws_security_group.test.id
+  ip_protocol                  = "tcp"
+  from_port                    = 22
+  to_port                      = 22
+  referenced_security_group_id = aws_security_group.test.id
+}
+
+resource "aws_vpc_security_group_egress_rule" "test_egress_0" {
+  security_group_id = aws_security_group.test.id
+  ip_protocol       = "-1"
+  cidr_ipv4         = "0.0.0.0/0"
+}
+
+resource "aws_vpc_security_group_egress_rule" "test_egress_1" {
+  security_group_id = aws_security_group.test.id
+  ip_protocol       = "-1"
+  cidr_ipv6         = "::/0"
+}
+`,
+		},
+		{
+			filename: "security_group_unmigratable.tf",
+			src: `
+resource "aws_security_group" "self" {
+  name = "self"
+
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = ["10.0.0.0/16"]
+    self        = var.allow_self
+  }
+}
+
+resource "aws_security_group" "dynamic" {
+  name = "dynamic"
+
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = ["10.0.0.0/16"]
+  }
+
+  dynamic "ingress" {
+    for_each = var.ports
+    content {
+      from_port   = ingress.value
+      to_port     = ingress.value
+      protocol    = "tcp"
+      cidr_blocks = ["10.0.0.0/16"]
+    }
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsSecurityGroup,
+			},
+			expectedMigrationFilename: "security_group_unmigratable_migrated.tf",
+			want: `
+resource "aws_security_group" "self" {
+  name = "self"
+
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = ["10.0.0.0/16"]
+    self        = var.allow_self
+  }
+  # TODO: Migrate the inline rules to 'aws_vpc_security_group_ingress_rule' and 'aws_vpc_security_group_egress_rule' resources; 'self' of an ingress rule cannot be determined statically
+}
+
+resource "aws_security_group" "dynamic" {
+  name = "dynamic"
+
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = ["10.0.0.0/16"]
+  }
+
+  dynamic "ingress" {
+    for_each = var.ports
+    content {
+      from_port   = ingress.value
+      to_port     = ingress.value
+      protocol    = "tcp"
+      cidr_blocks = ["10.0.0.0/16"]
+    }
+  }
+  # TODO: Migrate the inline rules to 'aws_vpc_security_group_ingress_rule' and 'aws_vpc_security_group_egress_rule' resources; dynamic ingress rules cannot be migrated
+}
+`,
+		},
+		{
+			filename: "route_table.tf",
+			src: `
+resource "aws_route_table" "test" {
+  vpc_id = aws_vpc.test.id
+
+  route {
+    cidr_block = "10.0.1.0/24"
+    gateway_id = aws_internet_gateway.test.id
+  }
+
+  route {
+    ipv6_cidr_block        = "::/0"
+    egress_only_gateway_id = aws_egress_only_internet_gateway.test.id
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsRouteTable,
+			},
+			expectedMigrationFilename: "route_table_migrated.tf",
+			want: `
+resource "aws_route_table" "test" {
+  vpc_id = aws_vpc.test.id
+
+
+}
+
+resource "aws_route" "test_10_0_1_0_24" {
+  route_table_id         = aws_route_table.test.id
+  destination_cidr_block = "10.0.1.0/24"
+  gateway_id             = aws_internet_gateway.test.id
+}
+
+resource "aws_route" "test_ipv6_0" {
+  route_table_id              = aws_route_table.test.id
+  destination_ipv6_cidr_block = "::/0"
+  egress_only_gateway_id      = aws_egress_only_internet_gateway.test.id
+}
+`,
+		},
+		{
+			filename: "network_acl.tf",
+			src: `
+resource "aws_network_acl" "test" {
+  vpc_id = aws_vpc.test.id
+
+  egress {
+    protocol   = "tcp"
+    rule_no    = 200
+    action     = "allow"
+    cidr_block = "10.3.0.0/18"
+    from_port  = 443
+    to_port    = 443
+  }
+
+  ingress {
+    protocol   = "tcp"
+    rule_no    = 100
+    action     = "allow"
+    cidr_block = "10.3.0.0/18"
+    from_port  = 80
+    to_port    = 80
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsNetworkAcl,
+			},
+			expectedMigrationFilename: "network_acl_migrated.tf",
+			want: `
+resource "aws_network_acl" "test" {
+  vpc_id = aws_vpc.test.id
+
+
+}
+
+resource "aws_network_acl_rule" "test_egress_200" {
+  network_acl_id = aws_network_acl.test.id
+  egress         = true
+  rule_action    = "allow"
+  cidr_block     = "10.3.0.0/18"
+  from_port      = 443
+  protocol       = "tcp"
+  rule_number    = 200
+  to_port        = 443
+}
+
+resource "aws_network_acl_rule" "test_ingress_100" {
+  network_acl_id = aws_network_acl.test.id
+  egress         = false
+  rule_action    = "allow"
+  cidr_block     = "10.3.0.0/18"
+  from_port      = 80
+  protocol       = "tcp"
+  rule_number    = 100
+  to_port        = 80
+}
+`,
+		},
+		{
+			filename: "iam_role.tf",
+			src: `
+resource "aws_iam_role" "test" {
+  name               = "test"
+  assume_role_policy = data.aws_iam_policy_document.assume_role.json
+
+  managed_policy_arns = [
+    "arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess",
+    aws_iam_policy.test.arn,
+  ]
+
+  inline_policy {
+    name   = "my-inline-policy"
+    policy = data.aws_iam_policy_document.inline.json
+  }
+
+  inline_policy {}
+}
+
+resource "aws_iam_role" "dynamic" {
+  name                = "dynamic"
+  assume_role_policy  = data.aws_iam_policy_document.assume_role.json
+  managed_policy_arns = var.policy_arns
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsIamRole,
+			},
+			expectedMigrationFilename: "iam_role_migrated.tf",
+			want: `
+resource "aws_iam_role" "test" {
+  name               = "test"
+  assume_role_policy = data.aws_iam_policy_document.assume_role.json
+
+
+
+}
+
+resource "aws_iam_role" "dynamic" {
+  name               = "dynamic"
+  assume_role_policy = data.aws_iam_policy_document.assume_role.json
+}
+
+resource "aws_iam_role_policy" "test_my_inline_policy" {
+  name   = "my-inline-policy"
+  role   = aws_iam_role.test.name
+  policy = data.aws_iam_policy_document.inline.json
+}
+
+resource "aws_iam_role_policy_attachment" "test_AmazonS3ReadOnlyAccess" {
+  role       = aws_iam_role.test.name
+  policy_arn = "arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess"
+}
+
+resource "aws_iam_role_policy_attachment" "test_policy_attachment_1" {
+  role       = aws_iam_role.test.name
+  policy_arn = aws_iam_policy.test.arn
+}
+
+resource "aws_iam_role_policy_attachment" "dynamic_policy_attachment" {
+  for_each = toset(var.policy_arns)
+
+  role       = aws_iam_role.dynamic.name
+  policy_arn = each.value
+}
 `,
 		},
 		{
@@ -159,6 +1734,41 @@ resource "aws_s3_bucket_acl" "test_acl" {
 				ProviderVersion: "latest",
 			},
 		},
+		{
+			filename: "valid.tf.json",
+			src: `{
+  "resource": {
+    "aws_s3_bucket": {
+      "test": {
+        "bucket": "tf-acc-test-1234",
+        "acl": "private"
+      }
+    }
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			expectedMigrationFilename: "valid_migrated.tf.json",
+			want: `{
+  "resource": {
+    "aws_s3_bucket": {
+      "test": {
+        "bucket": "tf-acc-test-1234"
+      }
+    },
+    "aws_s3_bucket_acl": {
+      "test_acl": {
+        "bucket": "${aws_s3_bucket.test.id}",
+        "acl": "private"
+      }
+    }
+  }
+}
+`,
+		},
 	}
 	for _, tc := range cases {
 		fs := afero.NewMemMapFs()
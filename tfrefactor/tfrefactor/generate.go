@@ -0,0 +1,163 @@
+package tfrefactor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// generateLabels are the labels of the generate blocks of .hcl files e.g. terragrunt.hcl
+// whose contents are Terraform configurations to migrate e.g.
+//
+//	generate "provider" {
+//	  path     = "provider.tf"
+//	  contents = <<EOF
+//	provider "aws" {
+//	  region = "us-east-1"
+//	}
+//	EOF
+//	}
+var generateLabels = []string{"provider", "versions"}
+
+// isGenerateFile returns whether a file is an .hcl file which may contain generate blocks.
+// Hidden files e.g. .tfrefactor.hcl and .terraform.lock.hcl are not.
+func isGenerateFile(filename string) bool {
+	return filepath.Ext(filename) == ".hcl" && !strings.HasPrefix(filepath.Base(filename), ".")
+}
+
+// MigrateGenerateBlocks reads an .hcl file e.g. terragrunt.hcl from io.Reader, migrates the configurations in
+// the heredoc contents of its generate "provider" and "versions" blocks and writes the result to io.Writer.
+// Interpolations in the contents are left unchanged for Terragrunt to render.
+// Nothing is written if no configuration was changed.
+func MigrateGenerateBlocks(r io.Reader, w io.Writer, filename string, o Option) ([]string, error) {
+	input, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %s", err)
+	}
+
+	f, diags := hclwrite.ParseConfig(input, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %s", filename, strings.TrimSpace(diags.Error()))
+	}
+
+	var migrations []string
+	var changed bool
+
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "generate" || len(block.Labels()) != 1 || !isGenerateLabel(block.Labels()[0]) {
+			continue
+		}
+
+		attr := block.Body().GetAttribute("contents")
+		if attr == nil {
+			continue
+		}
+
+		tokens := attr.Expr().BuildTokens(nil)
+		if len(tokens) < 2 || tokens[0].Type != hclsyntax.TokenOHeredoc || tokens[len(tokens)-1].Type != hclsyntax.TokenCHeredoc {
+			log.Printf("[WARN] Unable to migrate generate %q block in %s: contents must be a heredoc", block.Labels()[0], filename)
+			continue
+		}
+
+		name := fmt.Sprintf("%s:generate.%s", filename, block.Labels()[0])
+		contents := tokens[1 : len(tokens)-1].Bytes()
+
+		// The contents of an indented heredoc are migrated without their indentation
+		indent := ""
+		if bytes.HasPrefix(tokens[0].Bytes, []byte("<<-")) {
+			indent = heredocIndent(contents)
+			contents = unindent(contents, indent)
+		}
+
+		// The contents are compared with the original as an update of the provider version alone is a migration
+		out, _, newResourceNames, err := migrateHCL(contents, name, o)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, newResourceNames...)
+
+		if bytes.Equal(out, contents) {
+			log.Printf("[DEBUG] no migration of %s", name)
+			continue
+		}
+
+		migrated := bytes.TrimLeft(hclwrite.Format(out), "\n")
+		if indent != "" {
+			migrated = reindent(migrated, indent)
+		}
+
+		block.Body().SetAttributeRaw("contents", hclwrite.Tokens{
+			tokens[0],
+			{Type: hclsyntax.TokenStringLit, Bytes: migrated},
+			tokens[len(tokens)-1],
+		})
+		changed = true
+	}
+
+	if !changed {
+		return migrations, nil
+	}
+
+	if _, err := w.Write(f.Bytes()); err != nil {
+		return migrations, fmt.Errorf("failed to write output: %s", err)
+	}
+
+	return migrations, nil
+}
+
+func isGenerateLabel(label string) bool {
+	for _, l := range generateLabels {
+		if l == label {
+			return true
+		}
+	}
+
+	return false
+}
+
+// heredocIndent returns the smallest indentation of the non-empty lines of the contents of a heredoc.
+func heredocIndent(contents []byte) string {
+	var indent string
+	first := true
+	for _, line := range strings.Split(string(contents), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first || len(lineIndent) < len(indent) {
+			indent = lineIndent
+			first = false
+		}
+	}
+
+	return indent
+}
+
+func unindent(contents []byte, indent string) []byte {
+	lines := strings.Split(string(contents), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, indent)
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func reindent(contents []byte, indent string) []byte {
+	lines := strings.Split(string(contents), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
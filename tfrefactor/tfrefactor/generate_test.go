@@ -0,0 +1,158 @@
+package tfrefactor
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrateGenerateBlocks(t *testing.T) {
+	cases := []struct {
+		src            string
+		want           string
+		wantMigrations []string
+	}{
+		{
+			src: `
+generate "provider" {
+  path      = "provider.tf"
+  if_exists = "overwrite_terragrunt"
+  contents  = <<EOF
+provider "aws" {
+  region              = "${local.region}"
+  s3_force_path_style = true
+}
+EOF
+}
+
+generate "versions" {
+  path     = "versions.tf"
+  contents = <<-EOF
+    terraform {
+      required_providers {
+        aws = {
+          source  = "hashicorp/aws"
+          version = "3.74.0"
+        }
+      }
+    }
+
+    resource "aws_s3_bucket" "test" {
+      acl = "private"
+    }
+  EOF
+}
+`,
+			want: `
+generate "provider" {
+  path      = "provider.tf"
+  if_exists = "overwrite_terragrunt"
+  contents  = <<EOF
+provider "aws" {
+  region            = "${local.region}"
+  s3_use_path_style = true
+}
+EOF
+}
+
+generate "versions" {
+  path     = "versions.tf"
+  contents = <<-EOF
+    terraform {
+      required_providers {
+        aws = {
+          source  = "hashicorp/aws"
+          version = "4.0.0"
+        }
+      }
+    }
+
+    resource "aws_s3_bucket" "test" {
+    }
+
+    resource "aws_s3_bucket_acl" "test_acl" {
+      bucket = aws_s3_bucket.test.id
+      acl    = "private"
+    }
+  EOF
+}
+`,
+			wantMigrations: []string{
+				"aws_s3_bucket_acl.test_acl,aws_s3_bucket.test",
+			},
+		},
+		{
+			src: `
+generate "backend" {
+  path     = "backend.tf"
+  contents = <<EOF
+resource "aws_s3_bucket" "test" {
+  acl = "private"
+}
+EOF
+}
+
+generate "provider" {
+  path     = "provider.tf"
+  contents = "provider \"aws\" {}"
+}
+`,
+			want: "",
+		},
+		{
+			src: `
+generate "versions" {
+  path     = "versions.tf"
+  contents = <<EOF
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "3.74.0"
+    }
+  }
+}
+EOF
+}
+`,
+			want: `
+generate "versions" {
+  path     = "versions.tf"
+  contents = <<EOF
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "4.0.0"
+    }
+  }
+}
+EOF
+}
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		o := Option{
+			MigratorType:    "resource",
+			ProviderVersion: "latest",
+			ResourceType:    ResourceTypeAwsS3Bucket,
+		}
+
+		w := &bytes.Buffer{}
+		migrations, err := MigrateGenerateBlocks(strings.NewReader(tc.src), w, "terragrunt.hcl", o)
+		if err != nil {
+			t.Fatalf("MigrateGenerateBlocks() with src = %s returns unexpected err: %s", tc.src, err)
+		}
+
+		if got := w.String(); got != tc.want {
+			t.Errorf("MigrateGenerateBlocks() with src = %s returns %s, but want = %s", tc.src, got, tc.want)
+		}
+
+		if !reflect.DeepEqual(migrations, tc.wantMigrations) {
+			t.Errorf("MigrateGenerateBlocks() with src = %s returns migrations %#v, but want = %#v", tc.src, migrations, tc.wantMigrations)
+		}
+	}
+}
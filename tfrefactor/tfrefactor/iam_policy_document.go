@@ -0,0 +1,107 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// iamPolicyDocumentRenames maps the deprecated arguments of the aws_iam_policy_document data source
+// to their list-valued replacements.
+var iamPolicyDocumentRenames = map[string]string{
+	SourceJson:   SourcePolicyDocuments,
+	OverrideJson: OverridePolicyDocuments,
+}
+
+// ProviderAwsIamPolicyDocumentMigrator migrates the "source_json" and "override_json" arguments of
+// aws_iam_policy_document data sources, deprecated in v4.0.0 of the provider, to
+// "source_policy_documents" and "override_policy_documents".
+type ProviderAwsIamPolicyDocumentMigrator struct {
+	arguments        *ArgumentFilter
+	names            *NameFilter
+	newResourceNames []string
+
+	// ignore annotation of the block being migrated, if any
+	annotation *ignoreAnnotation
+}
+
+func NewProviderAwsIamPolicyDocumentMigrator(arguments *ArgumentFilter, names *NameFilter) (Migrator, error) {
+	return &ProviderAwsIamPolicyDocumentMigrator{
+		arguments: arguments,
+		names:     names,
+	}, nil
+}
+
+func (m *ProviderAwsIamPolicyDocumentMigrator) SkipResourceName(address string) bool {
+	if m == nil {
+		return false
+	}
+
+	return m.names.Skip(address)
+}
+
+func (m *ProviderAwsIamPolicyDocumentMigrator) SkipArgument(arg string) bool {
+	if m == nil {
+		return false
+	}
+
+	if m.annotation.skipArgument(arg) {
+		return true
+	}
+
+	return m.arguments.Skip(arg)
+}
+
+func (m *ProviderAwsIamPolicyDocumentMigrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating (%s) data sources: empty file", DataSourceTypeAwsIamPolicyDocument)
+	}
+
+	fileAnnotation := fileIgnoreAnnotation(f)
+
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		if block.Type() != "data" || len(labels) != 2 || labels[0] != DataSourceTypeAwsIamPolicyDocument {
+			continue
+		}
+
+		m.annotation = blockIgnoreAnnotation(block, fileAnnotation)
+		if m.SkipResourceName(blockAddress(block)) || m.annotation.skipResource() {
+			continue
+		}
+
+		dataSourcePath := fmt.Sprintf("data.%s", strings.Join(labels, "."))
+		log.Printf("[INFO] Found %s\n", dataSourcePath)
+
+		// Iterate in a fixed order for deterministic logging
+		for _, name := range []string{SourceJson, OverrideJson} {
+			newName := iamPolicyDocumentRenames[name]
+			if m.SkipArgument(name) || block.Body().GetAttribute(name) == nil {
+				continue
+			}
+
+			attr := renameAttribute(block.Body(), name, newName)
+			if attr == nil {
+				log.Printf("[WARN] Unable to migrate %s in %s: %s already exists", name, dataSourcePath, newName)
+				appendTodoComment(block.Body(), fmt.Sprintf("Add the value of '%s' to '%s'", name, newName))
+				continue
+			}
+
+			// The replacement arguments accept a list of policy documents
+			block.Body().SetAttributeRaw(newName, listTokens(attr.Expr().BuildTokens(nil)))
+
+			log.Printf("	  ✓ Migrated %s to %s in %s", name, newName, dataSourcePath)
+		}
+	}
+
+	return nil
+}
+
+func (m *ProviderAwsIamPolicyDocumentMigrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
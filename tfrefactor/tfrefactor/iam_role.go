@@ -0,0 +1,279 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ProviderAwsIamRoleMigrator migrates the deprecated "inline_policy" blocks and "managed_policy_arns" argument
+// of aws_iam_role resources to aws_iam_role_policy and aws_iam_role_policy_attachment resources.
+type ProviderAwsIamRoleMigrator struct {
+	arguments        *ArgumentFilter
+	names            *NameFilter
+	newResourceNames []string
+
+	// ignore annotation of the block being migrated, if any
+	annotation *ignoreAnnotation
+}
+
+func NewProviderAwsIamRoleMigrator(arguments *ArgumentFilter, names *NameFilter) (Migrator, error) {
+	return &ProviderAwsIamRoleMigrator{
+		arguments: arguments,
+		names:     names,
+	}, nil
+}
+
+func (m *ProviderAwsIamRoleMigrator) SkipResourceName(address string) bool {
+	if m == nil {
+		return false
+	}
+
+	return m.names.Skip(address)
+}
+
+func (m *ProviderAwsIamRoleMigrator) SkipArgument(arg string) bool {
+	if m == nil {
+		return false
+	}
+
+	if m.annotation.skipArgument(arg) {
+		return true
+	}
+
+	return m.arguments.Skip(arg)
+}
+
+func (m *ProviderAwsIamRoleMigrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating (%s) resources: empty file", ResourceTypeAwsIamRole)
+	}
+
+	fileAnnotation := fileIgnoreAnnotation(f)
+
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		if block.Type() != "resource" || len(labels) != 2 || labels[0] != ResourceTypeAwsIamRole {
+			continue
+		}
+
+		m.annotation = blockIgnoreAnnotation(block, fileAnnotation)
+		if m.SkipResourceName(blockAddress(block)) || m.annotation.skipResource() {
+			continue
+		}
+
+		rolePath := strings.Join(labels, ".")
+		log.Printf("[INFO] Found %s\n", rolePath)
+
+		// Special Attribute Handling i.e. for_each and count
+		hasInstances := block.Body().GetAttribute("count") != nil || block.Body().GetAttribute("for_each") != nil
+
+		var newBlocks []*hclwrite.Block
+
+		if !m.SkipArgument(InlinePolicy) {
+			newBlocks = append(newBlocks, m.migrateInlinePolicies(f, block)...)
+		}
+
+		if !m.SkipArgument(ManagedPolicyArns) {
+			newBlocks = append(newBlocks, m.migrateManagedPolicyArns(f, block)...)
+		}
+
+		if hasInstances {
+			for _, newBlock := range newBlocks {
+				appendTodoComment(newBlock.Body(), fmt.Sprintf("Replace 'role' argument value with correct instance index e.g. %s.%s[count.index].name", labels[0], labels[1]))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *ProviderAwsIamRoleMigrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
+
+// migrateInlinePolicies creates an aws_iam_role_policy resource for each "inline_policy" block of a role.
+// The import ID of each is the role name and policy name separated by a colon e.g. role:policy.
+func (m *ProviderAwsIamRoleMigrator) migrateInlinePolicies(f *hclwrite.File, block *hclwrite.Block) []*hclwrite.Block {
+	labels := block.Labels()
+	rolePath := strings.Join(labels, ".")
+
+	var newBlocks []*hclwrite.Block
+	var i int
+
+	// Policy names which differ only in punctuation e.g. a-b and a_b have the same suffix
+	suffixes := make(map[string]bool)
+
+	for _, inlinePolicy := range block.Body().Blocks() {
+		if inlinePolicy.Type() == "dynamic" && len(inlinePolicy.Labels()) == 1 && inlinePolicy.Labels()[0] == InlinePolicy {
+			log.Printf("[WARN] Unable to migrate dynamic %s blocks in %s", InlinePolicy, rolePath)
+			continue
+		}
+
+		if inlinePolicy.Type() != InlinePolicy {
+			continue
+		}
+
+		block.Body().RemoveBlock(inlinePolicy)
+
+		// An empty inline_policy block removes all inline policies not managed by Terraform
+		nameAttr := inlinePolicy.Body().GetAttribute("name")
+		policyAttr := inlinePolicy.Body().GetAttribute(Policy)
+		if policyAttr == nil {
+			continue
+		}
+
+		suffix := fmt.Sprintf("%s_%d", Policy, i)
+		i++
+		var importID string
+		if name, ok := literalString(nameAttr); ok && name != "" {
+			suffix = resourceNameSuffix(name)
+			importID = fmt.Sprintf("{id}:%s", name)
+		}
+
+		if suffixes[suffix] {
+			suffix = fmt.Sprintf("%s_%d", suffix, len(newBlocks))
+		}
+		suffixes[suffix] = true
+
+		f.Body().AppendNewline()
+
+		newlabels := []string{ResourceTypeAwsIamRolePolicy, fmt.Sprintf("%s_%s", labels[1], suffix)}
+		newBlock := f.Body().AppendNewBlock(block.Type(), newlabels)
+
+		if nameAttr != nil {
+			newBlock.Body().SetAttributeRaw("name", nameAttr.Expr().BuildTokens(nil))
+		}
+		newBlock.Body().SetAttributeTraversal("role", hcl.Traversal{
+			hcl.TraverseRoot{
+				Name: fmt.Sprintf("%s.%s.name", labels[0], labels[1]),
+			},
+		})
+		newBlock.Body().SetAttributeRaw(Policy, policyAttr.Expr().BuildTokens(nil))
+
+		newBlocks = append(newBlocks, newBlock)
+		m.appendMigration(ResourceTypeAwsIamRolePolicy, newlabels[1], rolePath, importID)
+	}
+
+	return newBlocks
+}
+
+// migrateManagedPolicyArns creates an aws_iam_role_policy_attachment resource for each element of the
+// "managed_policy_arns" argument of a role, or a single resource with for_each if it is an expression.
+// The import ID of each is the role name and policy ARN separated by a slash e.g. role/arn.
+func (m *ProviderAwsIamRoleMigrator) migrateManagedPolicyArns(f *hclwrite.File, block *hclwrite.Block) []*hclwrite.Block {
+	labels := block.Labels()
+	rolePath := strings.Join(labels, ".")
+
+	attr := block.Body().GetAttribute(ManagedPolicyArns)
+	if attr == nil {
+		return nil
+	}
+
+	block.Body().RemoveAttribute(ManagedPolicyArns)
+
+	appendAttachmentBlock := func(suffix string, forEach *hclwrite.Attribute) *hclwrite.Block {
+		f.Body().AppendNewline()
+
+		newBlock := f.Body().AppendNewBlock(block.Type(), []string{ResourceTypeAwsIamRolePolicyAttachment, fmt.Sprintf("%s_%s", labels[1], suffix)})
+		if forEach != nil {
+			newBlock.Body().SetAttributeTraversal("for_each", hcl.Traversal{
+				hcl.TraverseRoot{
+					Name: fmt.Sprintf("toset(%s)", strings.TrimSpace(string(forEach.Expr().BuildTokens(nil).Bytes()))),
+				},
+			})
+			newBlock.Body().AppendNewline()
+		}
+
+		newBlock.Body().SetAttributeTraversal("role", hcl.Traversal{
+			hcl.TraverseRoot{
+				Name: fmt.Sprintf("%s.%s.name", labels[0], labels[1]),
+			},
+		})
+
+		return newBlock
+	}
+
+	items, ok := tupleItems(attr)
+	if !ok {
+		// ARNs given as an expression e.g. var.policy_arns are represented as a single resource with for_each
+		newBlock := appendAttachmentBlock(PolicyAttachment, attr)
+		newBlock.Body().SetAttributeTraversal("policy_arn", hcl.Traversal{
+			hcl.TraverseRoot{
+				Name: "each.value",
+			},
+		})
+
+		m.appendMigration(ResourceTypeAwsIamRolePolicyAttachment, fmt.Sprintf("%s_%s", labels[1], PolicyAttachment), rolePath, "")
+
+		return []*hclwrite.Block{newBlock}
+	}
+
+	var newBlocks []*hclwrite.Block
+
+	// Policies of different accounts or paths may have the same name
+	suffixes := make(map[string]bool)
+
+	for i, item := range items {
+		suffix := fmt.Sprintf("%s_%d", PolicyAttachment, i)
+		var importID string
+		if arn, ok := literalStringSource(item); ok && arn != "" {
+			// Named after the policy e.g. AmazonS3ReadOnlyAccess for arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess
+			suffix = resourceNameSuffix(arn[strings.LastIndex(arn, "/")+1:])
+			importID = fmt.Sprintf("{id}/%s", arn)
+		}
+
+		if suffixes[suffix] {
+			suffix = fmt.Sprintf("%s_%d", suffix, i)
+		}
+		suffixes[suffix] = true
+
+		newBlock := appendAttachmentBlock(suffix, nil)
+		newBlock.Body().SetAttributeTraversal("policy_arn", hcl.Traversal{
+			hcl.TraverseRoot{
+				Name: item,
+			},
+		})
+
+		newBlocks = append(newBlocks, newBlock)
+		m.appendMigration(ResourceTypeAwsIamRolePolicyAttachment, fmt.Sprintf("%s_%s", labels[1], suffix), rolePath, importID)
+	}
+
+	return newBlocks
+}
+
+// appendMigration records a new resource with its parent role and its import ID template, if known.
+func (m *ProviderAwsIamRoleMigrator) appendMigration(resourceType, name, rolePath, importID string) {
+	log.Printf("	  ✓ Created %s.%s", resourceType, name)
+
+	// The role name alone is not the import ID of the new resource, so it is not included in the migrations
+	if importID == "" {
+		log.Printf("[WARN] Unable to determine import ID of %s.%s: it must be imported manually, once per instance if it has for_each", resourceType, name)
+		return
+	}
+
+	m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s,%s", resourceType, name, rolePath, importID))
+}
+
+// literalStringSource returns the value of an expression's source text if it is a literal string.
+func literalStringSource(src string) (string, bool) {
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return "", false
+	}
+
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() || v.IsNull() || !v.IsKnown() || v.Type() != cty.String {
+		return "", false
+	}
+
+	return v.AsString(), true
+}
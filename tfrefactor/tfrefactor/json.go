@@ -0,0 +1,794 @@
+package tfrefactor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Files in the Terraform JSON syntax (.tf.json) are migrated by converting them to the native syntax,
+// which the migrators operate on, and converting the migrated configuration back to JSON.
+// Properties keep their order; comments in "//" properties are converted to "#" comments and back,
+// so ignore annotations can be given as e.g. {"//": "tfrefactor:ignore"}.
+
+const (
+	jsonFileSuffix = ".tf.json"
+
+	// jsonCommentKey is the property of a JSON object which is a comment
+	jsonCommentKey = "//"
+)
+
+// isJSONFile returns whether a file is in the Terraform JSON syntax.
+func isJSONFile(filename string) bool {
+	return strings.HasSuffix(filename, jsonFileSuffix)
+}
+
+// jsonValue is a JSON value keeping the order of the properties of objects.
+type jsonValue struct {
+	kind jsonKind
+
+	// raw is the value of a string, or the source of a number, boolean or null
+	raw string
+
+	array  []*jsonValue
+	object []jsonProperty
+}
+
+type jsonKind int
+
+const (
+	jsonLiteral jsonKind = iota
+	jsonString
+	jsonArray
+	jsonObject
+)
+
+type jsonProperty struct {
+	key   string
+	value *jsonValue
+}
+
+// property returns the value of a property of an object, or nil.
+func (v *jsonValue) property(key string) *jsonValue {
+	for _, p := range v.object {
+		if p.key == key {
+			return p.value
+		}
+	}
+
+	return nil
+}
+
+func parseJSON(src []byte) (*jsonValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.UseNumber()
+
+	v, err := decodeJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected content after the JSON object")
+	}
+
+	return v, nil
+}
+
+func decodeJSON(dec *json.Decoder) (*jsonValue, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := t.(type) {
+	case json.Delim:
+		v := &jsonValue{kind: jsonArray}
+		if t == '{' {
+			v.kind = jsonObject
+		}
+
+		for dec.More() {
+			var key string
+			if v.kind == jsonObject {
+				k, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key = k.(string)
+			}
+
+			e, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			if v.kind == jsonObject {
+				v.object = append(v.object, jsonProperty{key: key, value: e})
+			} else {
+				v.array = append(v.array, e)
+			}
+		}
+
+		// closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return v, nil
+	case string:
+		return &jsonValue{kind: jsonString, raw: t}, nil
+	case json.Number:
+		return &jsonValue{kind: jsonLiteral, raw: t.String()}, nil
+	case bool:
+		return &jsonValue{kind: jsonLiteral, raw: strconv.FormatBool(t)}, nil
+	default:
+		return &jsonValue{kind: jsonLiteral, raw: "null"}, nil
+	}
+}
+
+func (v *jsonValue) encode(buf *bytes.Buffer) {
+	switch v.kind {
+	case jsonString:
+		writeJSONString(buf, v.raw)
+	case jsonArray:
+		buf.WriteByte('[')
+		for i, e := range v.array {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			e.encode(buf)
+		}
+		buf.WriteByte(']')
+	case jsonObject:
+		buf.WriteByte('{')
+		for i, p := range v.object {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, p.key)
+			buf.WriteByte(':')
+			p.value.encode(buf)
+		}
+		buf.WriteByte('}')
+	default:
+		buf.WriteString(v.raw)
+	}
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	// Encode only fails for unsupported types
+	_ = enc.Encode(s)
+	// Remove the newline written by Encode
+	buf.Truncate(buf.Len() - 1)
+}
+
+// jsonBlockSchema describes which properties of a JSON object are nested blocks, as the JSON syntax
+// does not distinguish them from arguments whose value is an object.
+type jsonBlockSchema struct {
+	// labels is the number of labels of the block
+	labels int
+
+	// blocks are the known nested block types
+	blocks map[string]*jsonBlockSchema
+
+	// If nested is true, other properties whose value is an object, or array of objects, are nested blocks
+	nested bool
+
+	// traversals are the arguments whose strings are references or types rather than templates
+	traversals map[string]bool
+}
+
+// jsonMapArguments are arguments whose value is a map rather than a nested block.
+var jsonMapArguments = map[string]bool{
+	"for_each":  true,
+	"tags":      true,
+	"tags_all":  true,
+	"triggers":  true,
+	"variables": true,
+}
+
+var jsonNestedBlockSchema = &jsonBlockSchema{
+	nested: true,
+	blocks: map[string]*jsonBlockSchema{
+		"dynamic": {labels: 1, nested: true},
+	},
+}
+
+var jsonResourceBlockSchema = &jsonBlockSchema{
+	labels: 2,
+	nested: true,
+	blocks: map[string]*jsonBlockSchema{
+		"connection": {},
+		"dynamic":    {labels: 1, nested: true},
+		"lifecycle": {
+			nested:     true,
+			traversals: map[string]bool{"ignore_changes": true, "replace_triggered_by": true},
+		},
+		"provisioner": {labels: 1, nested: true},
+	},
+	traversals: map[string]bool{"depends_on": true, "provider": true},
+}
+
+var jsonFileSchema = &jsonBlockSchema{
+	blocks: map[string]*jsonBlockSchema{
+		"check":  {labels: 1, nested: true},
+		"data":   jsonResourceBlockSchema,
+		"import": {traversals: map[string]bool{"provider": true, "to": true}},
+		"locals": {},
+		"module": {
+			labels:     1,
+			traversals: map[string]bool{"depends_on": true, "providers": true},
+		},
+		"moved": {traversals: map[string]bool{"from": true, "to": true}},
+		"output": {
+			labels:     1,
+			traversals: map[string]bool{"depends_on": true},
+		},
+		"provider": {labels: 1, nested: true},
+		"removed": {
+			blocks:     map[string]*jsonBlockSchema{"lifecycle": {}},
+			traversals: map[string]bool{"from": true},
+		},
+		"resource": jsonResourceBlockSchema,
+		"terraform": {
+			blocks: map[string]*jsonBlockSchema{
+				"backend":            {labels: 1},
+				"cloud":              {nested: true},
+				"provider_meta":      {labels: 1},
+				"required_providers": {},
+			},
+		},
+		"variable": {
+			labels:     1,
+			blocks:     map[string]*jsonBlockSchema{"validation": {}},
+			traversals: map[string]bool{"type": true},
+		},
+	},
+}
+
+// block returns the schema of a nested block type, or nil if it is not known to be a block.
+func (s *jsonBlockSchema) block(name string) *jsonBlockSchema {
+	if b, ok := s.blocks[name]; ok {
+		return b
+	}
+
+	if s.nested {
+		return jsonNestedBlockSchema
+	}
+
+	return nil
+}
+
+// isJSONBlock returns whether the value of a property of a block with nested blocks is a nested block,
+// i.e. an object, or array of objects, whose properties are all valid argument names.
+func isJSONBlock(name string, v *jsonValue) bool {
+	if jsonMapArguments[name] {
+		return false
+	}
+
+	values := []*jsonValue{v}
+	if v.kind == jsonArray {
+		if len(v.array) == 0 {
+			return false
+		}
+		values = v.array
+	}
+
+	for _, e := range values {
+		if e.kind != jsonObject {
+			return false
+		}
+
+		for _, p := range e.object {
+			if p.key != jsonCommentKey && !hclsyntax.ValidIdentifier(p.key) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// jsonPath returns the path of a block e.g. resource.aws_s3_bucket.example.versioning.
+func jsonPath(path string, elems ...string) string {
+	for _, e := range elems {
+		if path == "" {
+			path = e
+			continue
+		}
+		path = fmt.Sprintf("%s.%s", path, e)
+	}
+
+	return path
+}
+
+// jsonToHCL converts a configuration in the JSON syntax to the native syntax. It also returns the paths of
+// the blocks given as arrays of objects, which are converted back to arrays by hclToJSON.
+func jsonToHCL(src []byte, filename string) ([]byte, map[string]bool, error) {
+	v, err := parseJSON(src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JSON file %s: %s", filename, err)
+	}
+
+	if v.kind != jsonObject {
+		return nil, nil, fmt.Errorf("failed to parse JSON file %s: the root must be an object", filename)
+	}
+
+	c := &jsonConverter{arrays: make(map[string]bool)}
+	if err := c.writeBody(v, jsonFileSchema, ""); err != nil {
+		return nil, nil, fmt.Errorf("failed to convert JSON file %s: %s", filename, err)
+	}
+
+	return c.buf.Bytes(), c.arrays, nil
+}
+
+type jsonConverter struct {
+	buf    bytes.Buffer
+	arrays map[string]bool
+}
+
+func (c *jsonConverter) writeBody(v *jsonValue, schema *jsonBlockSchema, path string) error {
+	for _, p := range v.object {
+		if p.key == jsonCommentKey {
+			c.writeComment(p.value)
+			continue
+		}
+
+		if b := schema.block(p.key); b != nil && (schema.blocks[p.key] != nil || isJSONBlock(p.key, p.value)) {
+			if err := c.writeBlocks(p.key, nil, p.value, b, jsonPath(path, p.key)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if schema == jsonFileSchema {
+			return fmt.Errorf("unsupported block type %q", p.key)
+		}
+
+		if !hclsyntax.ValidIdentifier(p.key) {
+			return fmt.Errorf("%s: invalid argument name %q", path, p.key)
+		}
+
+		c.buf.WriteString(p.key)
+		c.buf.WriteString(" = ")
+		if err := c.writeExpr(p.value, schema.traversals[p.key]); err != nil {
+			return fmt.Errorf("%s: %s", jsonPath(path, p.key), err)
+		}
+		c.buf.WriteByte('\n')
+	}
+
+	return nil
+}
+
+// writeBlocks writes the blocks of a property, whose value is nested in an object for each label.
+func (c *jsonConverter) writeBlocks(blockType string, labels []string, v *jsonValue, schema *jsonBlockSchema, path string) error {
+	if v.kind == jsonArray {
+		for _, e := range v.array {
+			if err := c.writeBlocks(blockType, labels, e, schema, path); err != nil {
+				return err
+			}
+		}
+
+		if len(labels) == schema.labels {
+			c.arrays[path] = true
+		}
+		return nil
+	}
+
+	if v.kind != jsonObject {
+		return fmt.Errorf("%s: block must be a JSON object", path)
+	}
+
+	if len(labels) < schema.labels {
+		for _, p := range v.object {
+			if p.key == jsonCommentKey {
+				c.writeComment(p.value)
+				continue
+			}
+
+			if err := c.writeBlocks(blockType, append(labels[:len(labels):len(labels)], p.key), p.value, schema, jsonPath(path, p.key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	c.buf.WriteString(blockType)
+	for _, label := range labels {
+		c.buf.WriteByte(' ')
+		c.buf.Write(hclwrite.TokensForValue(cty.StringVal(label)).Bytes())
+	}
+	c.buf.WriteString(" {")
+
+	// The comment of a block is written on the line of its opening brace for its ignore annotations
+	body := &jsonValue{kind: jsonObject}
+	if comment := v.property(jsonCommentKey); comment != nil {
+		c.buf.WriteByte(' ')
+		c.writeComment(comment)
+	} else {
+		c.buf.WriteByte('\n')
+	}
+
+	for _, p := range v.object {
+		if p.key != jsonCommentKey {
+			body.object = append(body.object, p)
+		}
+	}
+
+	if err := c.writeBody(body, schema, path); err != nil {
+		return err
+	}
+
+	c.buf.WriteString("}\n")
+
+	return nil
+}
+
+func (c *jsonConverter) writeComment(v *jsonValue) {
+	text := v.raw
+	if v.kind != jsonString {
+		var buf bytes.Buffer
+		v.encode(&buf)
+		text = buf.String()
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		c.buf.WriteString(strings.TrimRight("# "+line, " "))
+		c.buf.WriteByte('\n')
+	}
+}
+
+func (c *jsonConverter) writeExpr(v *jsonValue, traversal bool) error {
+	switch v.kind {
+	case jsonString:
+		// References may also be given as a template of a single interpolation
+		if traversal && !strings.HasPrefix(v.raw, "${") {
+			c.buf.WriteString(v.raw)
+			return nil
+		}
+		return c.writeTemplate(v.raw)
+	case jsonArray:
+		c.buf.WriteByte('[')
+		for i, e := range v.array {
+			if i > 0 {
+				c.buf.WriteString(", ")
+			}
+			if err := c.writeExpr(e, traversal); err != nil {
+				return err
+			}
+		}
+		c.buf.WriteByte(']')
+	case jsonObject:
+		c.buf.WriteString("{\n")
+		for _, p := range v.object {
+			c.buf.Write(hclwrite.TokensForValue(cty.StringVal(p.key)).Bytes())
+			c.buf.WriteString(" = ")
+			if err := c.writeExpr(p.value, traversal); err != nil {
+				return err
+			}
+			c.buf.WriteByte('\n')
+		}
+		c.buf.WriteByte('}')
+	default:
+		c.buf.WriteString(v.raw)
+	}
+
+	return nil
+}
+
+// writeTemplate writes a string of the JSON syntax, which is a template, as an expression.
+// A template of a single interpolation e.g. "${aws_s3_bucket.example.id}" is written as its expression.
+func (c *jsonConverter) writeTemplate(s string) error {
+	if !strings.Contains(s, "${") && !strings.Contains(s, "%{") {
+		c.buf.Write(hclwrite.TokensForValue(cty.StringVal(s)).Bytes())
+		return nil
+	}
+
+	src := []byte(s)
+	expr, diags := hclsyntax.ParseTemplate(src, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to parse template %q: %s", s, strings.TrimSpace(diags.Error()))
+	}
+
+	if wrap, ok := expr.(*hclsyntax.TemplateWrapExpr); ok {
+		r := wrap.Wrapped.Range()
+		c.buf.Write(src[r.Start.Byte:r.End.Byte])
+		return nil
+	}
+
+	// Escape the literal parts of the template for a quoted template
+	tokens, _ := hclsyntax.LexTemplate(src, "", hcl.Pos{Line: 1, Column: 1})
+	c.buf.WriteByte('"')
+	var pos int
+	for _, t := range tokens {
+		if t.Type != hclsyntax.TokenStringLit {
+			continue
+		}
+
+		c.buf.Write(src[pos:t.Range.Start.Byte])
+		c.buf.WriteString(escapeQuotedLiteral(string(src[t.Range.Start.Byte:t.Range.End.Byte])))
+		pos = t.Range.End.Byte
+	}
+	c.buf.Write(src[pos:])
+	c.buf.WriteByte('"')
+
+	return nil
+}
+
+var quotedLiteralReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func escapeQuotedLiteral(s string) string {
+	return quotedLiteralReplacer.Replace(s)
+}
+
+// hclToJSON converts a configuration in the native syntax, converted from the JSON syntax by jsonToHCL
+// and migrated, back to the JSON syntax. Expressions which are not literal values are written as templates.
+func hclToJSON(src []byte, filename string, arrays map[string]bool) ([]byte, error) {
+	f, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse migrated configuration of %s: %s", filename, strings.TrimSpace(diags.Error()))
+	}
+
+	tokens, _ := hclsyntax.LexConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+
+	c := &hclConverter{src: src, arrays: arrays}
+	for _, t := range tokens {
+		if t.Type == hclsyntax.TokenComment {
+			c.comments = append(c.comments, t)
+		}
+	}
+
+	v := c.body(f.Body.(*hclsyntax.Body), jsonFileSchema, "")
+	for _, b := range c.blocks {
+		if len(b.value.array) == 1 && !arrays[b.path] {
+			*b.value = *b.value.array[0]
+		}
+	}
+
+	var buf, out bytes.Buffer
+	v.encode(&buf)
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to write JSON of %s: %s", filename, err)
+	}
+	out.WriteByte('\n')
+
+	return out.Bytes(), nil
+}
+
+type hclConverter struct {
+	src      []byte
+	arrays   map[string]bool
+	comments []hclsyntax.Token
+
+	// blocks are the arrays of blocks of each path, written as an object if there is only one
+	blocks []hclBlocks
+}
+
+type hclBlocks struct {
+	path  string
+	value *jsonValue
+}
+
+func (c *hclConverter) body(body *hclsyntax.Body, schema *jsonBlockSchema, path string) *jsonValue {
+	v := &jsonValue{kind: jsonObject}
+
+	if comment := c.comment(body); comment != "" {
+		v.object = append(v.object, jsonProperty{key: jsonCommentKey, value: &jsonValue{kind: jsonString, raw: comment}})
+	}
+
+	type item struct {
+		start int
+		attr  *hclsyntax.Attribute
+		block *hclsyntax.Block
+	}
+
+	var items []item
+	for _, attr := range body.Attributes {
+		items = append(items, item{start: attr.SrcRange.Start.Byte, attr: attr})
+	}
+	for _, block := range body.Blocks {
+		items = append(items, item{start: block.TypeRange.Start.Byte, block: block})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].start < items[j].start
+	})
+
+	for _, i := range items {
+		if i.attr != nil {
+			v.object = append(v.object, jsonProperty{key: i.attr.Name, value: c.expr(i.attr.Expr, schema.traversals[i.attr.Name])})
+			continue
+		}
+
+		blockSchema := schema.block(i.block.Type)
+		if blockSchema == nil {
+			blockSchema = &jsonBlockSchema{}
+		}
+
+		// Nest the block in an object for each label
+		keys := append([]string{i.block.Type}, i.block.Labels...)
+		target := v
+		for _, key := range keys[:len(keys)-1] {
+			next := target.property(key)
+			if next == nil {
+				next = &jsonValue{kind: jsonObject}
+				target.object = append(target.object, jsonProperty{key: key, value: next})
+			}
+			target = next
+		}
+
+		blockPath := jsonPath(path, keys...)
+		key := keys[len(keys)-1]
+		blocks := target.property(key)
+		if blocks == nil {
+			blocks = &jsonValue{kind: jsonArray}
+			target.object = append(target.object, jsonProperty{key: key, value: blocks})
+			c.blocks = append(c.blocks, hclBlocks{path: blockPath, value: blocks})
+		}
+
+		blocks.array = append(blocks.array, c.body(i.block.Body, blockSchema, blockPath))
+	}
+
+	return v
+}
+
+// comment returns the text of the comments in a body, excluding its nested blocks.
+func (c *hclConverter) comment(body *hclsyntax.Body) string {
+	var lines []string
+	for _, t := range c.comments {
+		if !rangeContains(body.SrcRange, t.Range) {
+			continue
+		}
+
+		var nested bool
+		for _, block := range body.Blocks {
+			if rangeContains(block.Body.SrcRange, t.Range) {
+				nested = true
+				break
+			}
+		}
+		if nested {
+			continue
+		}
+
+		text := strings.TrimSpace(string(t.Bytes))
+		for _, prefix := range []string{"#", "//", "/*"} {
+			text = strings.TrimPrefix(text, prefix)
+		}
+		lines = append(lines, strings.TrimSpace(strings.TrimSuffix(text, "*/")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func rangeContains(r, other hcl.Range) bool {
+	return r.Start.Byte <= other.Start.Byte && other.End.Byte <= r.End.Byte
+}
+
+func (c *hclConverter) source(expr hclsyntax.Expression) string {
+	r := expr.Range()
+	return string(c.src[r.Start.Byte:r.End.Byte])
+}
+
+// expr returns the JSON value of an expression: a literal value, or a template.
+func (c *hclConverter) expr(expr hclsyntax.Expression, traversal bool) *jsonValue {
+	switch e := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		v := &jsonValue{kind: jsonArray}
+		for _, elem := range e.Exprs {
+			v.array = append(v.array, c.expr(elem, traversal))
+		}
+		return v
+	case *hclsyntax.ObjectConsExpr:
+		v := &jsonValue{kind: jsonObject}
+		for _, item := range e.Items {
+			key, diags := item.KeyExpr.Value(nil)
+			if diags.HasErrors() || !key.IsKnown() || key.IsNull() || key.Type() != cty.String {
+				return &jsonValue{kind: jsonString, raw: fmt.Sprintf("${%s}", c.source(expr))}
+			}
+			v.object = append(v.object, jsonProperty{key: key.AsString(), value: c.expr(item.ValueExpr, traversal)})
+		}
+		return v
+	case *hclsyntax.TemplateWrapExpr:
+		return &jsonValue{kind: jsonString, raw: fmt.Sprintf("${%s}", c.source(e.Wrapped))}
+	case *hclsyntax.TemplateExpr:
+		if template, ok := c.quotedTemplate(e); ok {
+			return &jsonValue{kind: jsonString, raw: template}
+		}
+	}
+
+	if traversal {
+		return &jsonValue{kind: jsonString, raw: c.source(expr)}
+	}
+
+	if len(expr.Variables()) == 0 {
+		if v, diags := expr.Value(nil); !diags.HasErrors() && v.IsWhollyKnown() {
+			switch {
+			case v.IsNull():
+				return &jsonValue{kind: jsonLiteral, raw: "null"}
+			case v.Type() == cty.String:
+				return &jsonValue{kind: jsonString, raw: escapeTemplate(v.AsString())}
+			case v.Type() == cty.Number:
+				return &jsonValue{kind: jsonLiteral, raw: v.AsBigFloat().Text('f', -1)}
+			case v.Type() == cty.Bool:
+				return &jsonValue{kind: jsonLiteral, raw: strconv.FormatBool(v.True())}
+			}
+		}
+	}
+
+	// A heredoc must end with a newline before the end of the interpolation
+	source := c.source(expr)
+	if strings.HasPrefix(source, "<<") {
+		source += "\n"
+	}
+
+	return &jsonValue{kind: jsonString, raw: fmt.Sprintf("${%s}", source)}
+}
+
+// quotedTemplate returns the template of a quoted template expression with interpolations,
+// whose literal parts are unescaped.
+func (c *hclConverter) quotedTemplate(e *hclsyntax.TemplateExpr) (string, bool) {
+	if e.IsStringLiteral() {
+		return "", false
+	}
+
+	src := []byte(c.source(e))
+	if len(src) < 2 || src[0] != '"' {
+		return "", false
+	}
+
+	tokens, diags := hclsyntax.LexExpression(src, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return "", false
+	}
+
+	var buf strings.Builder
+	var depth, pos int
+	for _, t := range tokens {
+		// Whitespace between the tokens of interpolations
+		buf.Write(src[pos:t.Range.Start.Byte])
+		pos = t.Range.End.Byte
+
+		switch t.Type {
+		case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		case hclsyntax.TokenOQuote, hclsyntax.TokenCQuote, hclsyntax.TokenEOF:
+			if depth == 0 {
+				continue
+			}
+		case hclsyntax.TokenQuotedLit:
+			if depth == 0 {
+				lit, err := strconv.Unquote(`"` + string(t.Bytes) + `"`)
+				if err != nil {
+					return "", false
+				}
+				buf.WriteString(lit)
+				continue
+			}
+		}
+
+		buf.Write(t.Bytes)
+	}
+
+	return buf.String(), true
+}
+
+var templateReplacer = strings.NewReplacer("${", "$${", "%{", "%%{")
+
+// escapeTemplate escapes a literal string so that it is not interpreted as a template.
+func escapeTemplate(s string) string {
+	return templateReplacer.Replace(s)
+}
@@ -0,0 +1,158 @@
+package tfrefactor
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONToHCLRoundTrip(t *testing.T) {
+	src := `{
+  "//": "Generated configuration",
+  "provider": {
+    "aws": [
+      {
+        "region": "us-east-1"
+      }
+    ]
+  },
+  "variable": {
+    "names": {
+      "type": "map(string)",
+      "default": {
+        "log-bucket": "logs"
+      }
+    }
+  },
+  "resource": {
+    "aws_s3_bucket": {
+      "test": {
+        "//": "tfrefactor:ignore",
+        "bucket": "${var.names[\"log-bucket\"]}-${terraform.workspace}",
+        "count": 1,
+        "tags": {
+          "aws:name": "logs"
+        },
+        "policy": "$${literal}",
+        "dynamic": {
+          "website": {
+            "for_each": "${var.websites}",
+            "content": {
+              "index_document": "${website.value}"
+            }
+          }
+        },
+        "depends_on": [
+          "aws_s3_bucket.other"
+        ]
+      }
+    }
+  }
+}
+`
+
+	h, arrays, err := jsonToHCL([]byte(src), "test.tf.json")
+	if err != nil {
+		t.Fatalf("jsonToHCL() returns unexpected err: %s", err)
+	}
+
+	got, err := hclToJSON(h, "test.tf.json", arrays)
+	if err != nil {
+		t.Fatalf("hclToJSON() with src = %s returns unexpected err: %s", h, err)
+	}
+
+	if string(got) != src {
+		t.Errorf("hclToJSON() returns %s, but want = %s", got, src)
+	}
+}
+
+func TestMigrateHCLJSON(t *testing.T) {
+	src := `{
+  "terraform": {
+    "required_providers": {
+      "aws": {
+        "source": "hashicorp/aws",
+        "version": "3.74.0"
+      }
+    }
+  },
+  "resource": {
+    "aws_s3_bucket": {
+      "test": {
+        "bucket": "tf-acc-test-1234",
+        "acl": "private",
+        "versioning": {
+          "enabled": true
+        }
+      },
+      "ignored": {
+        "//": "tfrefactor:ignore",
+        "acl": "private"
+      }
+    }
+  }
+}
+`
+
+	want := `{
+  "terraform": {
+    "required_providers": {
+      "aws": {
+        "source": "hashicorp/aws",
+        "version": "4.0.0"
+      }
+    }
+  },
+  "resource": {
+    "aws_s3_bucket": {
+      "test": {
+        "bucket": "tf-acc-test-1234"
+      },
+      "ignored": {
+        "//": "tfrefactor:ignore",
+        "acl": "private"
+      }
+    },
+    "aws_s3_bucket_acl": {
+      "test_acl": {
+        "bucket": "${aws_s3_bucket.test.id}",
+        "acl": "private"
+      }
+    },
+    "aws_s3_bucket_versioning": {
+      "test_versioning": {
+        "bucket": "${aws_s3_bucket.test.id}",
+        "versioning_configuration": {
+          "status": "Enabled"
+        }
+      }
+    }
+  }
+}
+`
+
+	o := Option{
+		MigratorType:    "resource",
+		ProviderVersion: "latest",
+		ResourceType:    ResourceTypeAwsS3Bucket,
+	}
+
+	w := &bytes.Buffer{}
+	migrations, err := MigrateHCL(strings.NewReader(src), w, "test.tf.json", o)
+	if err != nil {
+		t.Fatalf("MigrateHCL() returns unexpected err: %s", err)
+	}
+
+	if got := w.String(); got != want {
+		t.Errorf("MigrateHCL() returns %s, but want = %s", got, want)
+	}
+
+	wantMigrations := []string{
+		"aws_s3_bucket_acl.test_acl,aws_s3_bucket.test",
+		"aws_s3_bucket_versioning.test_versioning,aws_s3_bucket.test",
+	}
+	if !reflect.DeepEqual(migrations, wantMigrations) {
+		t.Errorf("MigrateHCL() returns migrations %#v, but want = %#v", migrations, wantMigrations)
+	}
+}
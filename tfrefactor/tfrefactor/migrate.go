@@ -1,9 +1,13 @@
 package tfrefactor
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
+	"regexp"
+	"strconv"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl/v2"
@@ -14,31 +18,150 @@ import (
 
 const v4 = "4.0.0"
 
+var majorVersionRegexp = regexp.MustCompile(`\d+`)
+
 type Migrator interface {
 	Migrate(file *hclwrite.File) error
 	Migrations() []string
 }
 
+// NewMigrator returns the migrator of a resource or data source type. Resource types with
+// declarative rules are migrated according to them, and types with a migrator plugin by the
+// plugin, in addition to any built-in migrator.
 func NewMigrator(o Option) (Migrator, error) {
+	names, err := o.NameFilter()
+	if err != nil {
+		return nil, err
+	}
+	arguments := o.ArgumentFilter()
+
+	var migrators multiMigrator
+
+	m, builtinErr := newBuiltinMigrator(o, arguments, names)
+	if builtinErr == nil {
+		migrators = append(migrators, m)
+	}
+
+	if o.MigratorType == "resource" && o.Rules.Resource(o.ResourceType) != nil {
+		rules, err := NewRulesMigrator(o.Rules, o.ResourceType, arguments, names)
+		if err != nil {
+			return nil, err
+		}
+		migrators = append(migrators, rules)
+	}
+
+	// Plugins run last as they replace the file with their migrated source
+	if path := FindPlugin(o.ResourceType, o.PluginDirs, o.PluginsPath); path != "" {
+		log.Printf("[DEBUG] found migrator plugin: %s", path)
+		migrators = append(migrators, newPluginMigrator(path, o.MigratorType, o.ResourceType, o.IgnoreArguments, o.OnlyArguments, o.IgnoreResourceNames, o.OnlyResourceNames, o.modules, o.plugins))
+	}
+
+	switch len(migrators) {
+	case 0:
+		// Resource arguments renamed or removed in v5 are migrated in every file,
+		// independently of the migrator of the resource type, if any
+		if providerMajorVersion(o.ProviderVersion) >= 5 {
+			log.Printf("[DEBUG] no migrator of %s: %s", o.ResourceType, builtinErr)
+			return migrators, nil
+		}
+		return nil, builtinErr
+	case 1:
+		return migrators[0], nil
+	}
+
+	return migrators, nil
+}
+
+// multiMigrator runs migrators in order, collecting their migrations.
+type multiMigrator []Migrator
+
+func (mm multiMigrator) Migrate(f *hclwrite.File) error {
+	for _, m := range mm {
+		if err := m.Migrate(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (mm multiMigrator) Migrations() []string {
+	var migrations []string
+	for _, m := range mm {
+		migrations = append(migrations, m.Migrations()...)
+	}
+
+	return migrations
+}
+
+func newBuiltinMigrator(o Option, arguments *ArgumentFilter, names *NameFilter) (Migrator, error) {
 	switch o.MigratorType {
 	case "resource":
 		switch o.ResourceType {
-		case "aws_s3_bucket":
-			return NewProviderAwsS3BucketMigrator(o.IgnoreArguments, o.IgnoreResourceNames)
+		case ResourceTypeAwsS3Bucket:
+			return NewProviderAwsS3BucketMigrator(arguments, names, o.OwnershipControls, o.Module)
+		case ResourceTypeAwsS3BucketObject:
+			return NewProviderAwsS3BucketObjectMigrator(names, o.ProviderVersion, o.Module)
+		case ResourceTypeAwsSecretsManagerSecret:
+			return NewProviderAwsSecretsManagerSecretMigrator(arguments, names)
+		case ResourceTypeAwsIamRole:
+			return NewProviderAwsIamRoleMigrator(arguments, names)
+		case ResourceTypeAwsNetworkAcl:
+			return NewProviderAwsNetworkAclMigrator(arguments, names)
+		case ResourceTypeAwsRouteTable:
+			return NewProviderAwsRouteTableMigrator(arguments, names)
+		case ResourceTypeAwsSecurityGroup:
+			return NewProviderAwsSecurityGroupMigrator(arguments, names)
 		default:
 			return nil, errors.Errorf("failed to create new migrator. unknown resource type: %s", o.ResourceType)
 		}
+	case "data":
+		switch o.ResourceType {
+		case DataSourceTypeAwsIamPolicyDocument:
+			return NewProviderAwsIamPolicyDocumentMigrator(arguments, names)
+		default:
+			return nil, errors.Errorf("failed to create new migrator. unknown data source type: %s", o.ResourceType)
+		}
 	default:
 		return nil, errors.Errorf("failed to create new migrator. unknown type: %s", o.MigratorType)
 	}
 }
 
+// MigrateHCL reads HCL from io.Reader, migrates resources and writes the result to io.Writer.
+// Files whose name ends with .tf.json are read and written in the JSON syntax.
+// Nothing is written if the configuration was not changed by the migrator.
 func MigrateHCL(r io.Reader, w io.Writer, filename string, o Option) ([]string, error) {
 	input, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read input: %s", err)
 	}
 
+	output, changed, migrations, err := migrateHCL(input, filename, o)
+	if err != nil || !changed {
+		return migrations, err
+	}
+
+	if _, err := w.Write(output); err != nil {
+		return migrations, fmt.Errorf("failed to write output: %s", err)
+	}
+
+	return migrations, nil
+}
+
+// migrateHCL migrates the configuration of a file and returns the result, whether it was changed by the migrator
+// and the migrations. The result includes the provider version update even if nothing else was changed.
+func migrateHCL(input []byte, filename string, o Option) ([]byte, bool, []string, error) {
+	var err error
+
+	// Configurations in the JSON syntax are migrated in the native syntax
+	var jsonArrays map[string]bool
+	if isJSONFile(filename) {
+		input, jsonArrays, err = jsonToHCL(input, filename)
+		if err != nil {
+			return nil, false, nil, err
+		}
+	}
+
 	f, diags := hclwrite.ParseConfig(input, filename, hcl.Pos{Line: 1, Column: 1})
 	if diags != nil {
 		var errs *multierror.Error
@@ -47,7 +170,7 @@ func MigrateHCL(r io.Reader, w io.Writer, filename string, o Option) ([]string,
 				errs = multierror.Append(errs, fmt.Errorf(diag.Error()))
 			}
 		}
-		return nil, errs.ErrorOrNil()
+		return nil, false, nil, errs.ErrorOrNil()
 	}
 
 	// Migrate Provider Version(s)
@@ -58,28 +181,81 @@ func MigrateHCL(r io.Reader, w io.Writer, filename string, o Option) ([]string,
 
 		p, err := tfupdate.NewProviderUpdater("aws", o.ProviderVersion)
 		if err != nil {
-			return nil, fmt.Errorf("error creating tfupdate.ProviderUpdater: %w", err)
+			return nil, false, nil, fmt.Errorf("error creating tfupdate.ProviderUpdater: %w", err)
 		}
 
 		if err := p.Update(f); err != nil {
-			return nil, fmt.Errorf("error updating provider configurations to %s: %s", o.ProviderVersion, err)
+			return nil, false, nil, fmt.Errorf("error updating provider configurations to %s: %s", o.ProviderVersion, err)
 		}
 	}
 
 	m, err := NewMigrator(o)
 	if err != nil {
-		return nil, err
+		return nil, false, nil, err
+	}
+
+	before := f.Bytes()
+
+	// Migrate Provider Configuration(s) for the new major version
+	if providerMajorVersion(o.ProviderVersion) >= 4 {
+		p, err := NewProviderAwsConfigurationMigrator()
+		if err != nil {
+			return nil, false, nil, err
+		}
+
+		if err := p.Migrate(f); err != nil {
+			return nil, false, nil, fmt.Errorf("error migrating provider configurations to %s: %s", o.ProviderVersion, err)
+		}
+	}
+
+	// Migrate Resource Argument(s) renamed or removed in v5
+	if providerMajorVersion(o.ProviderVersion) >= 5 {
+		names, err := o.NameFilter()
+		if err != nil {
+			return nil, false, nil, err
+		}
+
+		p, err := NewProviderAwsV5Migrator(o.ArgumentFilter(), names)
+		if err != nil {
+			return nil, false, nil, err
+		}
+
+		if err := p.Migrate(f); err != nil {
+			return nil, false, nil, fmt.Errorf("error migrating resources to %s: %s", o.ProviderVersion, err)
+		}
 	}
 
 	if err = m.Migrate(f); err != nil {
-		return m.Migrations(), err
+		return nil, false, m.Migrations(), err
 	}
 
 	output := f.BuildTokens(nil).Bytes()
 
-	if _, err := w.Write(output); err != nil {
-		return m.Migrations(), fmt.Errorf("failed to write output: %s", err)
+	// Migrations may modify existing resources without creating new ones,
+	// so the configuration is only changed if the output differs.
+	changed := !bytes.Equal(before, output)
+
+	if isJSONFile(filename) {
+		output, err = hclToJSON(hclwrite.Format(output), filename, jsonArrays)
+		if err != nil {
+			return nil, false, m.Migrations(), err
+		}
+	}
+
+	return output, changed, m.Migrations(), nil
+}
+
+// providerMajorVersion returns the major version of a provider version constraint
+// e.g. 4 for "~> 4.0", or 0 if it cannot be determined.
+func providerMajorVersion(constraint string) int {
+	if constraint == "latest" {
+		constraint = v4
+	}
+
+	major, err := strconv.Atoi(majorVersionRegexp.FindString(constraint))
+	if err != nil {
+		return 0
 	}
 
-	return m.Migrations(), nil
+	return major
 }
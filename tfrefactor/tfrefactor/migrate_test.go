@@ -0,0 +1,330 @@
+package tfrefactor
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+func TestMigrateHCLMigrations(t *testing.T) {
+	cases := []struct {
+		src  string
+		o    Option
+		want []string
+
+		// migrated source, if asserted
+		wantSrc string
+	}{
+		{
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+  acl    = "private"
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsS3Bucket,
+			},
+			want: []string{
+				"aws_s3_bucket_acl.test_acl,aws_s3_bucket.test",
+			},
+		},
+		{
+			src: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+  acl    = "private"
+
+  versioning {
+    enabled = true
+  }
+
+  logging {
+    target_bucket = "logs"
+  }
+
+  dynamic "website" {
+    for_each = var.website
+    content {
+      index_document = website.value.index_document
+    }
+  }
+}
+`,
+			o: Option{
+				MigratorType:  "resource",
+				ResourceType:  ResourceTypeAwsS3Bucket,
+				OnlyArguments: []string{Versioning, Website},
+			},
+			want: []string{
+				"aws_s3_bucket_versioning.test_versioning,aws_s3_bucket.test",
+				"aws_s3_bucket_website_configuration.test_website_configuration,aws_s3_bucket.test",
+			},
+			wantSrc: `
+resource "aws_s3_bucket" "test" {
+  bucket = "tf-acc-test-1234"
+  acl    = "private"
+
+
+  logging {
+    target_bucket = "logs"
+  }
+
+}
+
+resource "aws_s3_bucket_versioning" "test_versioning" {
+  bucket = aws_s3_bucket.test.id
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+resource "aws_s3_bucket_website_configuration" "test_website_configuration" {
+  for_each = var.website
+
+  bucket = aws_s3_bucket.test.id
+  index_document {
+    suffix = each.value.index_document
+  }
+}
+`,
+		},
+		{
+			src: `
+resource "aws_route_table" "test" {
+  route {
+    cidr_block = "10.0.1.0/24"
+    gateway_id = aws_internet_gateway.test.id
+  }
+
+  route {
+    cidr_block     = var.cidr_block
+    nat_gateway_id = aws_nat_gateway.test.id
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsRouteTable,
+			},
+			want: []string{
+				"aws_route.test_10_0_1_0_24,aws_route_table.test,{id}_10.0.1.0/24",
+			},
+		},
+		{
+			src: `
+resource "aws_network_acl" "test" {
+  ingress {
+    protocol   = 6
+    rule_no    = 100
+    action     = "allow"
+    cidr_block = "10.3.0.0/18"
+    from_port  = 80
+    to_port    = 80
+  }
+
+  ingress {
+    protocol   = var.protocol
+    rule_no    = 110
+    action     = "allow"
+    cidr_block = "10.3.0.0/18"
+    from_port  = 443
+    to_port    = 443
+  }
+
+  egress {
+    protocol   = "-1"
+    rule_no    = 200
+    action     = "allow"
+    cidr_block = "0.0.0.0/0"
+    from_port  = 0
+    to_port    = 0
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsNetworkAcl,
+			},
+			want: []string{
+				"aws_network_acl_rule.test_ingress_100,aws_network_acl.test,{id}:100:6:false",
+				"aws_network_acl_rule.test_egress_200,aws_network_acl.test,{id}:200:-1:true",
+			},
+		},
+		{
+			src: `
+resource "aws_network_acl" "test" {
+  ingress {
+    protocol   = 6
+    rule_no    = var.rule_no
+    action     = "allow"
+    cidr_block = "10.3.0.0/18"
+    from_port  = 80
+    to_port    = 80
+  }
+
+  ingress {
+    protocol   = 6
+    rule_no    = 1
+    action     = "allow"
+    cidr_block = "10.3.0.0/18"
+    from_port  = 443
+    to_port    = 443
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsNetworkAcl,
+			},
+			want: []string{
+				"aws_network_acl_rule.test_ingress_1,aws_network_acl.test,{id}:1:6:false",
+			},
+			wantSrc: `
+resource "aws_network_acl" "test" {
+
+}
+
+resource "aws_network_acl_rule" "test_ingress_rule_0" {
+  network_acl_id = aws_network_acl.test.id
+  egress         = false
+  rule_action    = "allow"
+  cidr_block     = "10.3.0.0/18"
+  from_port      = 80
+  protocol       = 6
+  rule_number    = var.rule_no
+  to_port        = 80
+}
+
+resource "aws_network_acl_rule" "test_ingress_1" {
+  network_acl_id = aws_network_acl.test.id
+  egress         = false
+  rule_action    = "allow"
+  cidr_block     = "10.3.0.0/18"
+  from_port      = 443
+  protocol       = 6
+  rule_number    = 1
+  to_port        = 443
+}
+`,
+		},
+		{
+			src: `
+resource "aws_security_group" "test" {
+  ingress {
+    protocol    = "tcp"
+    from_port   = 443
+    to_port     = 443
+    cidr_blocks = ["10.0.0.0/8"]
+  }
+
+  egress {
+    protocol        = "-1"
+    from_port       = 0
+    to_port         = 0
+    security_groups = concat(var.a, var.b)
+    self            = true
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsSecurityGroup,
+			},
+			want: []string{
+				"aws_vpc_security_group_ingress_rule.test_ingress_0,aws_security_group.test,lookup:ingress protocol=tcp from_port=443 to_port=443 cidr_ipv4=10.0.0.0/8",
+				"aws_vpc_security_group_egress_rule.test_egress_0,aws_security_group.test,lookup:egress protocol=-1 from_port=0 to_port=0 referenced_security_group_id=each.value_of_toset(concat(var.a;_var.b))",
+				"aws_vpc_security_group_egress_rule.test_egress_1,aws_security_group.test,lookup:egress protocol=-1 from_port=0 to_port=0 referenced_security_group_id=self",
+			},
+		},
+		{
+			src: `
+resource "aws_iam_role" "test" {
+  managed_policy_arns = ["arn:aws:iam::aws:policy/ReadOnlyAccess"]
+
+  inline_policy {
+    name   = "inline"
+    policy = data.aws_iam_policy_document.inline.json
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsIamRole,
+			},
+			want: []string{
+				"aws_iam_role_policy.test_inline,aws_iam_role.test,{id}:inline",
+				"aws_iam_role_policy_attachment.test_ReadOnlyAccess,aws_iam_role.test,{id}/arn:aws:iam::aws:policy/ReadOnlyAccess",
+			},
+		},
+		{
+			src: `
+resource "aws_iam_role" "test" {
+  managed_policy_arns = [
+    "arn:aws:iam::aws:policy/ReadOnly",
+    "arn:aws:iam::123456789012:policy/ReadOnly",
+  ]
+}
+
+resource "aws_iam_role" "other" {
+  managed_policy_arns = var.policy_arns
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsIamRole,
+			},
+			want: []string{
+				"aws_iam_role_policy_attachment.test_ReadOnly,aws_iam_role.test,{id}/arn:aws:iam::aws:policy/ReadOnly",
+				"aws_iam_role_policy_attachment.test_ReadOnly_1,aws_iam_role.test,{id}/arn:aws:iam::123456789012:policy/ReadOnly",
+			},
+		},
+		{
+			src: `
+resource "aws_iam_role" "test" {
+  inline_policy {
+    name   = "a-b"
+    policy = data.aws_iam_policy_document.a.json
+  }
+
+  inline_policy {
+    name   = "a_b"
+    policy = data.aws_iam_policy_document.b.json
+  }
+}
+`,
+			o: Option{
+				MigratorType: "resource",
+				ResourceType: ResourceTypeAwsIamRole,
+			},
+			want: []string{
+				"aws_iam_role_policy.test_a_b,aws_iam_role.test,{id}:a-b",
+				"aws_iam_role_policy.test_a_b_1,aws_iam_role.test,{id}:a_b",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		w := &bytes.Buffer{}
+		got, err := MigrateHCL(strings.NewReader(tc.src), w, "test.tf", tc.o)
+		if err != nil {
+			t.Fatalf("MigrateHCL() with o = %#v returns unexpected err: %s", tc.o, err)
+		}
+
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("MigrateHCL() with o = %#v returns migrations %#v, but want = %#v", tc.o, got, tc.want)
+		}
+
+		if tc.wantSrc == "" {
+			continue
+		}
+
+		if gotSrc := string(hclwrite.Format(w.Bytes())); gotSrc != tc.wantSrc {
+			t.Errorf("MigrateHCL() with o = %#v returns %s, but want = %s", tc.o, gotSrc, tc.wantSrc)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// Module is a directory of Terraform configuration files.
+// It is used to statically resolve expressions which reference values defined
+// elsewhere in the module e.g. locals, variable defaults and files.
+type Module struct {
+	fs  afero.Fs
+	dir string
+
+	// locals which can be determined statically
+	locals map[string]cty.Value
+
+	// variables with a default value
+	variables map[string]cty.Value
+
+	// sources of the modules called with a local path, by name
+	calls map[string]string
+
+	// addresses of the resources and data sources annotated with "tfrefactor:ignore"
+	ignoredAddresses map[string]bool
+}
+
+// LoadModule reads the locals, variable defaults and ignore annotations defined in the .tf and .tf.json files
+// of a given directory. Files which cannot be parsed are skipped.
+func LoadModule(fs afero.Fs, dir string) (*Module, error) {
+	m := &Module{
+		fs:        fs,
+		dir:       dir,
+		locals:    make(map[string]cty.Value),
+		variables: make(map[string]cty.Value),
+		calls:     make(map[string]string),
+
+		ignoredAddresses: make(map[string]bool),
+	}
+
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dir: %s", err)
+	}
+
+	pendingLocals := make(map[string]hcl.Expression)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isConfigFile(entry.Name()) {
+			continue
+		}
+
+		filename := filepath.Join(dir, entry.Name())
+		src, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %s", err)
+		}
+
+		if isJSONFile(filename) {
+			if src, _, err = jsonToHCL(src, filename); err != nil {
+				log.Printf("[WARN] Unable to parse %s: %s", filename, err)
+				continue
+			}
+		}
+
+		f, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			log.Printf("[WARN] Unable to parse %s: %s", filename, diags)
+			continue
+		}
+
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		// Annotations are comments, which are only kept by hclwrite
+		if wf, diags := hclwrite.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1}); !diags.HasErrors() {
+			fileAnnotation := fileIgnoreAnnotation(wf)
+			for _, block := range wf.Body().Blocks() {
+				if (block.Type() == "resource" || block.Type() == "data") && blockIgnoreAnnotation(block, fileAnnotation).skipResource() {
+					m.ignoredAddresses[blockAddress(block)] = true
+				}
+			}
+		}
+
+		for _, block := range body.Blocks {
+			switch block.Type {
+			case "locals":
+				for name, attr := range block.Body.Attributes {
+					pendingLocals[name] = attr.Expr
+				}
+			case "variable":
+				if len(block.Labels) != 1 {
+					continue
+				}
+				attr, ok := block.Body.Attributes["default"]
+				if !ok {
+					continue
+				}
+				// Variable defaults cannot reference other values
+				if v, diags := attr.Expr.Value(nil); !diags.HasErrors() {
+					m.variables[block.Labels[0]] = v
+				}
+			case "module":
+				if len(block.Labels) != 1 {
+					continue
+				}
+				attr, ok := block.Body.Attributes["source"]
+				if !ok {
+					continue
+				}
+				// Only modules in the same repository can be migrated with the configuration
+				v, diags := attr.Expr.Value(nil)
+				if diags.HasErrors() || v.IsNull() || v.Type() != cty.String {
+					continue
+				}
+				if source := v.AsString(); strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+					m.calls[block.Labels[0]] = source
+				}
+			}
+		}
+	}
+
+	// Locals can reference one another, so evaluate them until no more can be resolved
+	for resolved := true; resolved; {
+		resolved = false
+		for name, expr := range pendingLocals {
+			v, diags := expr.Value(m.EvalContext())
+			if diags.HasErrors() || !v.IsWhollyKnown() {
+				continue
+			}
+			m.locals[name] = v
+			delete(pendingLocals, name)
+			resolved = true
+		}
+	}
+
+	return m, nil
+}
+
+// maxModuleDepth limits the nesting of module calls followed by LoadModuleAddresses.
+const maxModuleDepth = 16
+
+// LoadModuleAddresses returns the addresses of the modules called with a local source from the root
+// module in a given directory, by their directory e.g. {"modules/logs": ["module.logs"]}.
+// The address of the root module is "". A module called more than once has several addresses.
+func LoadModuleAddresses(fs afero.Fs, root string) (map[string][]string, error) {
+	addresses := make(map[string][]string)
+
+	var load func(dir, address string, depth int) error
+	load = func(dir, address string, depth int) error {
+		dir = filepath.Clean(dir)
+		addresses[dir] = append(addresses[dir], address)
+
+		if depth >= maxModuleDepth {
+			log.Printf("[WARN] Unable to load modules called from %s: too many nested modules", dir)
+			return nil
+		}
+
+		m, err := LoadModule(fs, dir)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(m.calls))
+		for name := range m.calls {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			source := filepath.Join(dir, m.calls[name])
+			if ok, _ := afero.DirExists(fs, source); !ok {
+				log.Printf("[DEBUG] module %s source not found: %s", name, source)
+				continue
+			}
+
+			child := fmt.Sprintf("module.%s", name)
+			if address != "" {
+				child = fmt.Sprintf("%s.%s", address, child)
+			}
+
+			if err := load(source, child, depth+1); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return addresses, load(root, "", 0)
+}
+
+// Ignored returns whether a resource or data source of the module is annotated with "tfrefactor:ignore"
+// e.g. aws_s3_bucket_object.example.
+func (m *Module) Ignored(address string) bool {
+	if m == nil {
+		return false
+	}
+
+	return m.ignoredAddresses[address]
+}
+
+// EvalContext returns an evaluation context with the statically known values of the module
+// and a subset of the Terraform functions which can be used to evaluate them.
+func (m *Module) EvalContext() *hcl.EvalContext {
+	if m == nil {
+		return nil
+	}
+
+	return &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"local": cty.ObjectVal(m.locals),
+			"var":   cty.ObjectVal(m.variables),
+			"path": cty.ObjectVal(map[string]cty.Value{
+				// paths are resolved relative to the module directory
+				"module": cty.StringVal("."),
+				"root":   cty.StringVal("."),
+			}),
+		},
+		Functions: m.functions(),
+	}
+}
+
+// Value returns the value of an attribute's expression if it can be evaluated statically
+// with the values of the module.
+func (m *Module) Value(attr *hclwrite.Attribute) (cty.Value, bool) {
+	if m == nil {
+		return literalValue(attr)
+	}
+
+	expr, diags := hclsyntax.ParseExpression(attr.Expr().BuildTokens(nil).Bytes(), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.NilVal, false
+	}
+
+	v, diags := expr.Value(m.EvalContext())
+	if diags.HasErrors() || !v.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+
+	return v, true
+}
+
+func (m *Module) functions() map[string]function.Function {
+	return map[string]function.Function{
+		"file":         m.fileFunc(),
+		"jsondecode":   stdlib.JSONDecodeFunc,
+		"jsonencode":   stdlib.JSONEncodeFunc,
+		"templatefile": m.templateFileFunc(),
+		"trimspace":    stdlib.TrimSpaceFunc,
+	}
+}
+
+func (m *Module) readFile(path string) ([]byte, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.dir, path)
+	}
+
+	return afero.ReadFile(m.fs, path)
+}
+
+func (m *Module) fileFunc() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			src, err := m.readFile(args[0].AsString())
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.StringVal(string(src)), nil
+		},
+	})
+}
+
+func (m *Module) templateFileFunc() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+			{
+				Name: "vars",
+				Type: cty.DynamicPseudoType,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			src, err := m.readFile(path)
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			expr, diags := hclsyntax.ParseTemplate(src, path, hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				return cty.NilVal, diags
+			}
+
+			vars := args[1]
+			if !vars.CanIterateElements() {
+				return cty.NilVal, fmt.Errorf("invalid vars value: must be a map")
+			}
+
+			ctx := &hcl.EvalContext{
+				Variables: vars.AsValueMap(),
+				Functions: m.functions(),
+			}
+
+			v, diags := expr.Value(ctx)
+			if diags.HasErrors() {
+				return cty.NilVal, diags
+			}
+			return v, nil
+		},
+	})
+}
@@ -0,0 +1,159 @@
+package tfrefactor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestModuleValue(t *testing.T) {
+	files := map[string]string{
+		"module/main.tf": `
+locals {
+  rules      = file("${path.module}/rules.json")
+  rules_copy = local.rules
+  templated  = templatefile("${path.module}/rules.tpl", { prefix = var.prefix })
+  unknown    = aws_s3_bucket.example.id
+}
+
+variable "prefix" {
+  default = "docs/"
+}
+
+variable "no_default" {}
+`,
+		"module/rules.json": `[{"Condition":{"KeyPrefixEquals":"docs/"}}]`,
+		"module/rules.tpl":  `[{"Condition":{"KeyPrefixEquals":"${prefix}"}}]`,
+	}
+
+	cases := []struct {
+		expr  string
+		want  cty.Value
+		known bool
+	}{
+		{
+			expr:  `"literal"`,
+			want:  cty.StringVal("literal"),
+			known: true,
+		},
+		{
+			expr:  `local.rules`,
+			want:  cty.StringVal(`[{"Condition":{"KeyPrefixEquals":"docs/"}}]`),
+			known: true,
+		},
+		{
+			expr:  `local.rules_copy`,
+			want:  cty.StringVal(`[{"Condition":{"KeyPrefixEquals":"docs/"}}]`),
+			known: true,
+		},
+		{
+			expr:  `local.templated`,
+			want:  cty.StringVal(`[{"Condition":{"KeyPrefixEquals":"docs/"}}]`),
+			known: true,
+		},
+		{
+			expr:  `file("rules.json")`,
+			want:  cty.StringVal(`[{"Condition":{"KeyPrefixEquals":"docs/"}}]`),
+			known: true,
+		},
+		{
+			expr:  `var.prefix`,
+			want:  cty.StringVal("docs/"),
+			known: true,
+		},
+		{
+			expr:  `var.no_default`,
+			known: false,
+		},
+		{
+			expr:  `local.unknown`,
+			known: false,
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	for name, src := range files {
+		if err := afero.WriteFile(fs, name, []byte(src), 0644); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+	}
+
+	module, err := LoadModule(fs, "module")
+	if err != nil {
+		t.Fatalf("LoadModule() returns unexpected err: %s", err)
+	}
+
+	for _, tc := range cases {
+		f, diags := hclwrite.ParseConfig([]byte("value = "+tc.expr), "", hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			t.Fatalf("failed to parse expression: %s", diags)
+		}
+
+		got, known := module.Value(f.Body().GetAttribute("value"))
+		if known != tc.known {
+			t.Errorf("Value() with expr = %s returns known = %t, but want = %t", tc.expr, known, tc.known)
+			continue
+		}
+
+		if tc.known && !got.RawEquals(tc.want) {
+			t.Errorf("Value() with expr = %s returns %#v, but want = %#v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestLoadModuleAddresses(t *testing.T) {
+	files := map[string]string{
+		"root/main.tf": `
+module "logs" {
+  source = "./modules/bucket"
+}
+
+module "assets" {
+  source = "./modules/bucket"
+}
+
+module "network" {
+  source = "./modules/network"
+}
+
+module "registry" {
+  source  = "terraform-aws-modules/s3-bucket/aws"
+  version = "~> 3.0"
+}
+`,
+		"root/modules/bucket/main.tf": `
+resource "aws_s3_bucket" "this" {}
+`,
+		"root/modules/network/main.tf": `
+module "flow_logs" {
+  source = "../bucket"
+}
+`,
+	}
+
+	fs := afero.NewMemMapFs()
+	for filename, src := range files {
+		if err := afero.WriteFile(fs, filename, []byte(src), 0644); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+	}
+
+	got, err := LoadModuleAddresses(fs, "root/")
+	if err != nil {
+		t.Fatalf("LoadModuleAddresses() returns unexpected err: %s", err)
+	}
+
+	want := map[string][]string{
+		"root":                 {""},
+		"root/modules/bucket":  {"module.assets", "module.logs", "module.network.module.flow_logs"},
+		"root/modules/network": {"module.network"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadModuleAddresses() returns %#v, but want = %#v", got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// NameFilter decides which resources and data sources to migrate by their address.
+// Patterns are either:
+//   - a glob e.g. log_* matched against the name, or against the full address including
+//     the module path if it contains a "." e.g. module.logs.aws_s3_bucket.*
+//   - a regular expression between slashes e.g. /^log_/ matched against both the name and the full address
+type NameFilter struct {
+	ignore []namePattern
+	only   []namePattern
+
+	// addresses of the module containing the configuration e.g. module.logs,
+	// or "" for the root module
+	modules []string
+}
+
+type namePattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// NewNameFilter returns a filter skipping resources matching any of the ignore patterns or,
+// if there are any only patterns, not matching one of them. A module called more than once
+// has several addresses; its resources are skipped unless all of them are to be migrated.
+func NewNameFilter(ignoreNames, onlyNames, modules []string) (*NameFilter, error) {
+	ignore, err := parseNamePatterns(ignoreNames)
+	if err != nil {
+		return nil, err
+	}
+
+	only, err := parseNamePatterns(onlyNames)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modules) == 0 {
+		modules = []string{""}
+	}
+
+	return &NameFilter{
+		ignore:  ignore,
+		only:    only,
+		modules: modules,
+	}, nil
+}
+
+func parseNamePatterns(patterns []string) ([]namePattern, error) {
+	var result []namePattern
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+
+		if len(p) > 2 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			re, err := regexp.Compile(p[1 : len(p)-1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile regexp for resource name %s: %s", p, err)
+			}
+			result = append(result, namePattern{re: re})
+			continue
+		}
+
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("failed to parse glob for resource name %s: %s", p, err)
+		}
+		result = append(result, namePattern{glob: p})
+	}
+
+	return result, nil
+}
+
+// match returns whether a pattern matches a resource of the given name and full address.
+func (p namePattern) match(name, address string) bool {
+	if p.re != nil {
+		return p.re.MatchString(name) || p.re.MatchString(address)
+	}
+
+	s := name
+	if strings.Contains(p.glob, ".") {
+		s = address
+	}
+
+	ok, _ := path.Match(p.glob, s)
+	return ok
+}
+
+// Skip returns whether to skip the resource or data source of an address in the module
+// e.g. aws_s3_bucket.example or data.aws_iam_policy_document.example. A nil filter skips nothing.
+func (nf *NameFilter) Skip(address string) bool {
+	if nf == nil {
+		return false
+	}
+
+	name := address[strings.LastIndex(address, ".")+1:]
+
+	for _, module := range nf.modules {
+		fullAddress := address
+		if module != "" {
+			fullAddress = fmt.Sprintf("%s.%s", module, address)
+		}
+
+		for _, p := range nf.ignore {
+			if p.match(name, fullAddress) {
+				return true
+			}
+		}
+
+		if len(nf.only) == 0 {
+			continue
+		}
+
+		var ok bool
+		for _, p := range nf.only {
+			if p.match(name, fullAddress) {
+				ok = true
+				break
+			}
+		}
+
+		if !ok {
+			return true
+		}
+	}
+
+	return false
+}
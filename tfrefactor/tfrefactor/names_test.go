@@ -0,0 +1,113 @@
+package tfrefactor
+
+import (
+	"testing"
+)
+
+func TestNameFilter(t *testing.T) {
+	cases := []struct {
+		ignoreNames []string
+		onlyNames   []string
+		modules     []string
+		address     string
+		want        bool
+	}{
+		{
+			ignoreNames: []string{"example"},
+			address:     "aws_s3_bucket.example",
+			want:        true,
+		},
+		{
+			ignoreNames: []string{"example"},
+			address:     "aws_s3_bucket.example_logs",
+			want:        false,
+		},
+		{
+			ignoreNames: []string{"log_*"},
+			address:     "aws_s3_bucket.log_bucket",
+			want:        true,
+		},
+		{
+			ignoreNames: []string{"/^log_/"},
+			address:     "aws_s3_bucket.log_bucket",
+			want:        true,
+		},
+		{
+			ignoreNames: []string{`/^module\.logs\./`},
+			modules:     []string{"module.logs"},
+			address:     "aws_s3_bucket.this",
+			want:        true,
+		},
+		{
+			ignoreNames: []string{"module.logs.aws_s3_bucket.*"},
+			modules:     []string{"module.logs"},
+			address:     "aws_s3_bucket.this",
+			want:        true,
+		},
+		{
+			ignoreNames: []string{"module.logs.aws_s3_bucket.*"},
+			modules:     []string{"module.assets"},
+			address:     "aws_s3_bucket.this",
+			want:        false,
+		},
+		{
+			ignoreNames: []string{"module.logs.aws_s3_bucket.*"},
+			address:     "aws_s3_bucket.this",
+			want:        false,
+		},
+		{
+			// a module called more than once is skipped if any of its addresses is ignored
+			ignoreNames: []string{"module.logs.*"},
+			modules:     []string{"module.assets", "module.logs"},
+			address:     "aws_s3_bucket.this",
+			want:        true,
+		},
+		{
+			ignoreNames: []string{"data.aws_iam_policy_document.*"},
+			address:     "data.aws_iam_policy_document.example",
+			want:        true,
+		},
+		{
+			onlyNames: []string{"module.logs.*"},
+			modules:   []string{"module.logs"},
+			address:   "aws_s3_bucket.this",
+			want:      false,
+		},
+		{
+			onlyNames: []string{"module.logs.*"},
+			address:   "aws_s3_bucket.this",
+			want:      true,
+		},
+		{
+			onlyNames: []string{"module.logs.*"},
+			modules:   []string{"module.assets", "module.logs"},
+			address:   "aws_s3_bucket.this",
+			want:      true,
+		},
+		{
+			ignoreNames: []string{"this"},
+			onlyNames:   []string{"module.logs.*"},
+			modules:     []string{"module.logs"},
+			address:     "aws_s3_bucket.this",
+			want:        true,
+		},
+	}
+
+	for _, tc := range cases {
+		nf, err := NewNameFilter(tc.ignoreNames, tc.onlyNames, tc.modules)
+		if err != nil {
+			t.Fatalf("NewNameFilter() returns unexpected err: %s", err)
+		}
+
+		if got := nf.Skip(tc.address); got != tc.want {
+			t.Errorf("Skip() with ignoreNames = %v, onlyNames = %v, modules = %v and address = %s returns %t, but want = %t",
+				tc.ignoreNames, tc.onlyNames, tc.modules, tc.address, got, tc.want)
+		}
+	}
+
+	for _, pattern := range []string{"/[/", "log_["} {
+		if _, err := NewNameFilter([]string{pattern}, nil, nil); err == nil {
+			t.Errorf("NewNameFilter() with pattern = %s returns no error, but want an error", pattern)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// networkAclRuleRenames maps the arguments of inline network ACL rules to the arguments
+// of aws_network_acl_rule which were renamed.
+var networkAclRuleRenames = map[string]string{
+	"action":  "rule_action",
+	"rule_no": "rule_number",
+}
+
+// ProviderAwsNetworkAclMigrator migrates the inline "ingress" and "egress" blocks of aws_network_acl
+// resources to aws_network_acl_rule resources.
+type ProviderAwsNetworkAclMigrator struct {
+	arguments        *ArgumentFilter
+	names            *NameFilter
+	newResourceNames []string
+
+	// ignore annotation of the block being migrated, if any
+	annotation *ignoreAnnotation
+}
+
+func NewProviderAwsNetworkAclMigrator(arguments *ArgumentFilter, names *NameFilter) (Migrator, error) {
+	return &ProviderAwsNetworkAclMigrator{
+		arguments: arguments,
+		names:     names,
+	}, nil
+}
+
+func (m *ProviderAwsNetworkAclMigrator) SkipResourceName(address string) bool {
+	if m == nil {
+		return false
+	}
+
+	return m.names.Skip(address)
+}
+
+func (m *ProviderAwsNetworkAclMigrator) SkipArgument(arg string) bool {
+	if m == nil {
+		return false
+	}
+
+	if m.annotation.skipArgument(arg) {
+		return true
+	}
+
+	return m.arguments.Skip(arg)
+}
+
+func (m *ProviderAwsNetworkAclMigrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating (%s) resources: empty file", ResourceTypeAwsNetworkAcl)
+	}
+
+	fileAnnotation := fileIgnoreAnnotation(f)
+
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		if block.Type() != "resource" || len(labels) != 2 || labels[0] != ResourceTypeAwsNetworkAcl {
+			continue
+		}
+
+		m.annotation = blockIgnoreAnnotation(block, fileAnnotation)
+		if m.SkipResourceName(blockAddress(block)) || m.annotation.skipResource() {
+			continue
+		}
+
+		networkAclPath := strings.Join(labels, ".")
+		log.Printf("[INFO] Found %s\n", networkAclPath)
+
+		// Special Attribute Handling i.e. for_each and count
+		hasInstances := block.Body().GetAttribute("count") != nil || block.Body().GetAttribute("for_each") != nil
+
+		counts := map[string]int{}
+
+		for _, rule := range block.Body().Blocks() {
+			direction := rule.Type()
+			if direction == "dynamic" && len(rule.Labels()) == 1 {
+				if l := rule.Labels()[0]; l == Ingress || l == Egress {
+					log.Printf("[WARN] Unable to migrate dynamic %s rules in %s", l, networkAclPath)
+				}
+				continue
+			}
+
+			if (direction != Ingress && direction != Egress) || m.SkipArgument(direction) {
+				continue
+			}
+
+			block.Body().RemoveBlock(rule)
+			f.Body().AppendNewline()
+
+			// Rules are named after their rule number, which is also part of the import ID
+			// e.g. acl-7aaabd18:100:tcp:false, or their index if it cannot be determined
+			suffix := fmt.Sprintf("rule_%d", counts[direction])
+			counts[direction]++
+			var importID string
+
+			ruleNumber, ok := literalValue(rule.Body().GetAttribute("rule_no"))
+			if ok && !ruleNumber.IsNull() && ruleNumber.Type() == cty.Number {
+				n, _ := ruleNumber.AsBigFloat().Int64()
+				suffix = fmt.Sprintf("%d", n)
+
+				if protocol, ok := literalProtocol(rule.Body().GetAttribute("protocol")); ok {
+					importID = fmt.Sprintf("{id}:%d:%s:%t", n, protocol, direction == Egress)
+				}
+			}
+
+			newlabels := []string{ResourceTypeAwsNetworkAclRule, fmt.Sprintf("%s_%s_%s", labels[1], direction, suffix)}
+			newBlock := f.Body().AppendNewBlock(block.Type(), newlabels)
+
+			newBlock.Body().SetAttributeTraversal("network_acl_id", hcl.Traversal{
+				hcl.TraverseRoot{
+					Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
+				},
+			})
+			newBlock.Body().SetAttributeValue("egress", cty.BoolVal(direction == Egress))
+
+			// Expected: rule_no, action, protocol, cidr_block or ipv6_cidr_block, from_port, to_port, icmp_type, icmp_code
+			for _, k := range sortedAttributeNames(rule.Body()) {
+				newName := k
+				if n, ok := networkAclRuleRenames[k]; ok {
+					newName = n
+				}
+				newBlock.Body().SetAttributeRaw(newName, rule.Body().GetAttribute(k).Expr().BuildTokens(nil))
+			}
+
+			if hasInstances {
+				appendTodoComment(newBlock.Body(), fmt.Sprintf("Replace 'network_acl_id' argument value with correct instance index e.g. %s.%s[count.index].id", labels[0], labels[1]))
+			}
+
+			log.Printf("	  ✓ Created %s.%s", ResourceTypeAwsNetworkAclRule, newlabels[1])
+
+			if importID == "" {
+				// The network ACL ID alone is not the import ID of a rule, so the rule is not included in the migrations
+				log.Printf("[WARN] Unable to determine import ID of %s.%s: rule_no or protocol cannot be determined statically; it must be imported manually", ResourceTypeAwsNetworkAclRule, newlabels[1])
+				continue
+			}
+
+			m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s,%s", ResourceTypeAwsNetworkAclRule, newlabels[1], networkAclPath, importID))
+		}
+	}
+
+	return nil
+}
+
+func (m *ProviderAwsNetworkAclMigrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
+
+// literalProtocol returns the value of a "protocol" attribute if it is a literal string or number e.g. "tcp" or 6.
+func literalProtocol(attr *hclwrite.Attribute) (string, bool) {
+	v, ok := literalValue(attr)
+	if !ok || v.IsNull() {
+		return "", false
+	}
+
+	switch v.Type() {
+	case cty.String:
+		return v.AsString(), true
+	case cty.Number:
+		n, _ := v.AsBigFloat().Int64()
+		return fmt.Sprintf("%d", n), true
+	}
+
+	return "", false
+}
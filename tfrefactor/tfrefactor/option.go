@@ -2,6 +2,7 @@ package tfrefactor
 
 import (
 	"fmt"
+	"log"
 	"regexp"
 )
 
@@ -9,7 +10,8 @@ import (
 type Option struct {
 	MigratorType string
 
-	// ResourceType to migrate e.g. aws_s3_bucket
+	// ResourceType to migrate e.g. aws_s3_bucket, or the data source type
+	// e.g. aws_iam_policy_document when MigratorType is "data"
 	ResourceType string
 
 	// a new provider version constraint
@@ -24,15 +26,126 @@ type Option struct {
 	// An array of arguments to ignore
 	IgnoreArguments []string
 
-	// An array of resource names to ignore
+	// An array of arguments to migrate, ignoring all others if not empty
+	OnlyArguments []string
+
+	// An array of resource name patterns to ignore
 	IgnoreResourceNames []string
 
+	// An array of resource name patterns to migrate, ignoring all others if not empty
+	OnlyResourceNames []string
+
 	// An array of regular expression for paths to ignore.
 	IgnorePaths []*regexp.Regexp
+
+	// If an ownership controls flag is true, generates aws_s3_bucket_ownership_controls resources alongside
+	// non-private ACLs and aws_s3_bucket_public_access_block resources alongside public ACLs
+	OwnershipControls bool
+
+	// If an hcl files flag is true, also migrates the configurations in the generate "provider" and "versions"
+	// blocks of .hcl files e.g. terragrunt.hcl
+	HclFiles bool
+
+	// Declarative rules migrating resource types in addition to, or in place of, built-in migrators
+	Rules *Rules
+
+	// Directories searched for migrator plugins
+	PluginDirs []string
+
+	// If a plugins path flag is true, migrator plugins are also searched for on PATH
+	PluginsPath bool
+
+	// Addresses of the modules of each directory called by the root module being migrated
+	// e.g. ["module.logs"] for the directory of a module "logs" with a local source
+	ModuleAddresses map[string][]string
+
+	// Project configuration, whose directory overrides apply to the files of each directory
+	Config *Config
+
+	// If the provider version was given as a CLI flag, it is not overridden by directories of the configuration
+	explicitProviderVersion bool
+
+	// If the ownership controls flag was given as a CLI flag, it is not overridden by directories of the configuration
+	explicitOwnershipControls bool
+
+	// Module containing the configuration to migrate, used to resolve values
+	// defined outside of a single file e.g. locals and variables
+	Module *Module
+
+	// addresses of the module of the file being migrated
+	modules []string
+
+	// migrator plugin processes of the run, shared by the files being migrated
+	plugins *pluginClients
 }
 
-// NewOption returns an option.
-func NewOption(migratorType, resourceType, providerVersion string, csv, recursive bool, ignoreArguments, ignoreResourceNames, ignorePaths []string) (Option, error) {
+// NewOption returns an option, merging the CLI flags with the project configuration, if any.
+// Flags which were not given on the CLI are nil and take the value of the configuration, if any;
+// flags which were given take precedence over the configuration, even if they are the default
+// value (e.g. --csv=false or -p latest). Lists are combined.
+// The plugin settings of the configuration are only applied if allowed on the CLI, as a configuration
+// file of the configuration being migrated could otherwise run any executable.
+func NewOption(migratorType, resourceType string, providerVersion *string, csv, recursive, ownershipControls, hclFiles *bool, ignoreArguments, onlyArguments, ignoreResourceNames, onlyResourceNames, ignorePaths []string, allowConfigPlugins bool, config *Config) (Option, error) {
+	o := Option{
+		MigratorType:              migratorType,
+		ResourceType:              resourceType,
+		ProviderVersion:           "latest",
+		explicitProviderVersion:   providerVersion != nil,
+		explicitOwnershipControls: ownershipControls != nil,
+	}
+
+	var pluginDirs []string
+	var pluginsPath bool
+	if config != nil {
+		if config.ProviderVersion != "" {
+			o.ProviderVersion = config.ProviderVersion
+		}
+
+		o.Csv = config.Csv
+		o.Recursive = config.Recursive
+		o.HclFiles = config.HclFiles
+		ignorePaths = appendStrings(ignorePaths, config.IgnorePaths...)
+
+		switch {
+		case allowConfigPlugins:
+			for _, dir := range config.PluginDirs {
+				pluginDirs = append(pluginDirs, config.Path(dir))
+			}
+			pluginsPath = config.PluginsPath
+		case len(config.PluginDirs) > 0 || config.PluginsPath:
+			log.Printf("[WARN] Ignoring plugin_dirs and plugins_path of %s: allow them with --allow-config-plugins", config.Path(ConfigFilename))
+		}
+
+		if mc := config.Migrator(resourceType); mc != nil {
+			ignoreArguments = appendStrings(ignoreArguments, mc.IgnoreArguments...)
+			onlyArguments = appendStrings(onlyArguments, mc.OnlyArguments...)
+			ignoreResourceNames = appendStrings(ignoreResourceNames, mc.IgnoreNames...)
+			onlyResourceNames = appendStrings(onlyResourceNames, mc.OnlyNames...)
+			o.OwnershipControls = mc.OwnershipControls
+		}
+	}
+
+	if providerVersion != nil {
+		o.ProviderVersion = *providerVersion
+	}
+	if csv != nil {
+		o.Csv = *csv
+	}
+	if recursive != nil {
+		o.Recursive = *recursive
+	}
+	if ownershipControls != nil {
+		o.OwnershipControls = *ownershipControls
+	}
+	if hclFiles != nil {
+		o.HclFiles = *hclFiles
+	}
+
+	// Patterns are compiled for each module when migrating, so only validate them here
+	if _, err := NewNameFilter(ignoreResourceNames, onlyResourceNames, nil); err != nil {
+		return Option{}, err
+	}
+
 	regexps := make([]*regexp.Regexp, 0, len(ignorePaths))
 	for _, ignorePath := range ignorePaths {
 		if len(ignorePath) == 0 {
@@ -46,16 +159,48 @@ func NewOption(migratorType, resourceType, providerVersion string, csv, recursiv
 		regexps = append(regexps, r)
 	}
 
-	return Option{
-		MigratorType:        migratorType,
-		ResourceType:        resourceType,
-		ProviderVersion:     providerVersion,
-		Csv:                 csv,
-		Recursive:           recursive,
-		IgnoreArguments:     ignoreArguments,
-		IgnoreResourceNames: ignoreResourceNames,
-		IgnorePaths:         regexps,
-	}, nil
+	o.IgnoreArguments = ignoreArguments
+	o.OnlyArguments = onlyArguments
+	o.IgnoreResourceNames = ignoreResourceNames
+	o.OnlyResourceNames = onlyResourceNames
+	o.IgnorePaths = regexps
+	o.PluginDirs = pluginDirs
+	o.PluginsPath = pluginsPath
+	o.Config = config
+
+	return o, nil
+}
+
+// NameFilter returns the filter of the resources to migrate in the module of the file being migrated.
+func (o Option) NameFilter() (*NameFilter, error) {
+	return NewNameFilter(o.IgnoreResourceNames, o.OnlyResourceNames, o.modules)
+}
+
+// ArgumentFilter returns the filter of the arguments to migrate.
+func (o Option) ArgumentFilter() *ArgumentFilter {
+	return NewArgumentFilter(o.IgnoreArguments, o.OnlyArguments)
+}
+
+// ForDir returns the option for the files of a directory, with the overrides of the
+// directories of the project configuration which contain it.
+func (o Option) ForDir(dir string) Option {
+	for _, d := range o.Config.directories(dir) {
+		if d.ProviderVersion != "" && !o.explicitProviderVersion {
+			o.ProviderVersion = d.ProviderVersion
+		}
+
+		if mc := findMigratorConfig(d.Migrators, o.ResourceType); mc != nil {
+			o.IgnoreArguments = appendStrings(o.IgnoreArguments, mc.IgnoreArguments...)
+			o.OnlyArguments = appendStrings(o.OnlyArguments, mc.OnlyArguments...)
+			o.IgnoreResourceNames = appendStrings(o.IgnoreResourceNames, mc.IgnoreNames...)
+			o.OnlyResourceNames = appendStrings(o.OnlyResourceNames, mc.OnlyNames...)
+			if !o.explicitOwnershipControls {
+				o.OwnershipControls = o.OwnershipControls || mc.OwnershipControls
+			}
+		}
+	}
+
+	return o
 }
 
 // MatchIgnorePaths returns whether any of the ignore conditions are met.
@@ -68,3 +213,11 @@ func (o *Option) MatchIgnorePaths(path string) bool {
 
 	return false
 }
+
+// appendStrings returns a new slice of the elements of a followed by elems,
+// leaving the backing array of a unchanged.
+func appendStrings(a []string, elems ...string) []string {
+	result := make([]string, 0, len(a)+len(elems))
+	result = append(result, a...)
+	return append(result, elems...)
+}
@@ -0,0 +1,329 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// PluginPrefix is the prefix of the file name of migrator plugins, followed by the
+// resource or data source type they migrate e.g. tfrefactor-migrator-aws_lb.
+const PluginPrefix = "tfrefactor-migrator-"
+
+// PluginHandshake is the handshake between tfrefactor and migrator plugins.
+// Plugins must be built against the same protocol version.
+var PluginHandshake = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "TFREFACTOR_PLUGIN",
+	MagicCookieValue: "migrator",
+}
+
+const pluginName = "migrator"
+
+// MigrateRequest is the configuration passed to a migrator plugin.
+type MigrateRequest struct {
+	// "resource" or "data"
+	MigratorType string
+
+	// ResourceType to migrate e.g. aws_lb
+	ResourceType string
+
+	// HCL source of a configuration file, after built-in migrations
+	Src []byte
+
+	IgnoreArguments     []string
+	OnlyArguments       []string
+	IgnoreResourceNames []string
+	OnlyResourceNames   []string
+
+	// Addresses of the module of the configuration file e.g. module.logs, which can be
+	// passed to NewNameFilter with IgnoreResourceNames and OnlyResourceNames
+	ModuleAddresses []string
+}
+
+// MigrateResponse is the result of a migrator plugin.
+type MigrateResponse struct {
+	// HCL source of the migrated configuration file, or nil if it was not changed
+	Src []byte
+
+	// New resources to import
+	Migrations []PluginMigration
+
+	// Warnings are logged by tfrefactor e.g. arguments which could not be migrated
+	Warnings []string
+}
+
+// PluginMigration is a new resource created by a migrator plugin.
+type PluginMigration struct {
+	// Address of the new resource e.g. aws_lb_listener.example_listener
+	Address string
+
+	// Address of the resource it was migrated from e.g. aws_lb.example
+	Parent string
+
+	// An optional import ID template in which {id} is the ID of the parent resource
+	ImportID string
+}
+
+// ExternalMigrator is implemented by migrator plugins and served with ServePlugin.
+type ExternalMigrator interface {
+	Migrate(req MigrateRequest) (MigrateResponse, error)
+}
+
+// ServePlugin serves an external migrator from the main function of a plugin e.g.
+//
+//	func main() {
+//		tfrefactor.ServePlugin(&LoadBalancerMigrator{})
+//	}
+func ServePlugin(m ExternalMigrator) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: PluginHandshake,
+		Plugins: map[string]plugin.Plugin{
+			pluginName: &MigratorPlugin{Impl: m},
+		},
+	})
+}
+
+// MigratorPlugin is the go-plugin implementation of an external migrator over net/rpc.
+type MigratorPlugin struct {
+	Impl ExternalMigrator
+}
+
+func (p *MigratorPlugin) Server(*plugin.MuxBroker) (interface{}, error) {
+	return &migratorRPCServer{impl: p.Impl}, nil
+}
+
+func (p *MigratorPlugin) Client(b *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &migratorRPCClient{client: c}, nil
+}
+
+type migratorRPCServer struct {
+	impl ExternalMigrator
+}
+
+func (s *migratorRPCServer) Migrate(req MigrateRequest, resp *MigrateResponse) error {
+	r, err := s.impl.Migrate(req)
+	*resp = r
+	return err
+}
+
+type migratorRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *migratorRPCClient) Migrate(req MigrateRequest) (MigrateResponse, error) {
+	var resp MigrateResponse
+	err := c.client.Call("Plugin.Migrate", req, &resp)
+	return resp, err
+}
+
+// FindPlugin returns the path of the migrator plugin of a resource or data source type
+// in the given plugin directories, then PATH if searchPath is true, or "" if there is none.
+func FindPlugin(resourceType string, pluginDirs []string, searchPath bool) string {
+	name := PluginPrefix + resourceType
+
+	for _, dir := range pluginDirs {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+			continue
+		}
+
+		return path
+	}
+
+	// Executables on PATH are only run if explicitly enabled
+	if !searchPath {
+		return ""
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return ""
+	}
+
+	return path
+}
+
+// pluginClients are the migrator plugin processes of a run. Each plugin is started once
+// and shared by the migrators of every file.
+type pluginClients struct {
+	clients   map[string]*plugin.Client
+	externals map[string]ExternalMigrator
+}
+
+func newPluginClients() *pluginClients {
+	return &pluginClients{
+		clients:   make(map[string]*plugin.Client),
+		externals: make(map[string]ExternalMigrator),
+	}
+}
+
+// dispense returns the external migrator of a plugin, starting the plugin process on first use.
+func (c *pluginClients) dispense(path string) (ExternalMigrator, error) {
+	if external, ok := c.externals[path]; ok {
+		return external, nil
+	}
+
+	client := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig: PluginHandshake,
+		Plugins: map[string]plugin.Plugin{
+			pluginName: &MigratorPlugin{},
+		},
+		Cmd:     exec.Command(path),
+		Managed: true,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:   "plugin",
+			Output: log.Writer(),
+			Level:  hclog.Debug,
+		}),
+	})
+	c.clients[path] = client
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		return nil, fmt.Errorf("error starting migrator plugin (%s): %s", path, err)
+	}
+
+	raw, err := rpcClient.Dispense(pluginName)
+	if err != nil {
+		return nil, fmt.Errorf("error dispensing migrator plugin (%s): %s", path, err)
+	}
+
+	external, ok := raw.(ExternalMigrator)
+	if !ok {
+		return nil, fmt.Errorf("error dispensing migrator plugin (%s): unexpected type %T", path, raw)
+	}
+	c.externals[path] = external
+
+	return external, nil
+}
+
+// kill stops the plugin processes.
+func (c *pluginClients) kill() {
+	for path, client := range c.clients {
+		client.Kill()
+		delete(c.clients, path)
+		delete(c.externals, path)
+	}
+}
+
+// PluginMigrator migrates resources with an external migrator plugin. The plugin process
+// is started once per run and receives the HCL source of each file. Plugins own the whole
+// file: the migrated source they return replaces the file, including resources of other types.
+type PluginMigrator struct {
+	path                string
+	migratorType        string
+	resourceType        string
+	ignoreArguments     []string
+	onlyArguments       []string
+	ignoreResourceNames []string
+	onlyResourceNames   []string
+	modules             []string
+	newResourceNames    []string
+
+	// plugin processes of the run; a process is started for the file if nil
+	plugins *pluginClients
+
+	// external is dispensed from the plugin process if nil
+	external ExternalMigrator
+}
+
+func NewPluginMigrator(path, migratorType, resourceType string, ignoreArguments, onlyArguments, ignoreResourceNames, onlyResourceNames, modules []string) (Migrator, error) {
+	return newPluginMigrator(path, migratorType, resourceType, ignoreArguments, onlyArguments, ignoreResourceNames, onlyResourceNames, modules, nil), nil
+}
+
+func newPluginMigrator(path, migratorType, resourceType string, ignoreArguments, onlyArguments, ignoreResourceNames, onlyResourceNames, modules []string, plugins *pluginClients) *PluginMigrator {
+	return &PluginMigrator{
+		path:                path,
+		migratorType:        migratorType,
+		resourceType:        resourceType,
+		ignoreArguments:     ignoreArguments,
+		onlyArguments:       onlyArguments,
+		ignoreResourceNames: ignoreResourceNames,
+		onlyResourceNames:   onlyResourceNames,
+		modules:             modules,
+		plugins:             plugins,
+	}
+}
+
+func (m *PluginMigrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating (%s) resources: empty file", m.resourceType)
+	}
+
+	external := m.external
+	if external == nil {
+		plugins := m.plugins
+		if plugins == nil {
+			plugins = newPluginClients()
+			defer plugins.kill()
+		}
+
+		var err error
+		external, err = plugins.dispense(m.path)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := external.Migrate(MigrateRequest{
+		MigratorType:        m.migratorType,
+		ResourceType:        m.resourceType,
+		Src:                 f.Bytes(),
+		IgnoreArguments:     m.ignoreArguments,
+		OnlyArguments:       m.onlyArguments,
+		IgnoreResourceNames: m.ignoreResourceNames,
+		OnlyResourceNames:   m.onlyResourceNames,
+		ModuleAddresses:     m.modules,
+	})
+	if err != nil {
+		return fmt.Errorf("error migrating (%s) resources with plugin (%s): %s", m.resourceType, m.path, err)
+	}
+
+	for _, warning := range resp.Warnings {
+		log.Printf("[WARN] %s", warning)
+	}
+
+	for _, mr := range resp.Migrations {
+		log.Printf("	  ✓ Created %s", mr.Address)
+
+		if mr.ImportID == "" {
+			m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s,%s", mr.Address, mr.Parent))
+			continue
+		}
+
+		m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s,%s,%s", mr.Address, mr.Parent, mr.ImportID))
+	}
+
+	if resp.Src == nil {
+		return nil
+	}
+
+	migrated, diags := hclwrite.ParseConfig(resp.Src, m.path, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return fmt.Errorf("error parsing output of migrator plugin (%s): %s", m.path, strings.TrimSpace(diags.Error()))
+	}
+
+	// The migrated source replaces the file; blocks are no longer available to later migrators
+	f.Body().Clear()
+	f.Body().AppendUnstructuredTokens(migrated.BuildTokens(nil))
+
+	return nil
+}
+
+func (m *PluginMigrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
@@ -0,0 +1,196 @@
+package tfrefactor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// testExternalMigrator migrates the "listener" argument of aws_lb resources to an aws_lb_listener resource.
+type testExternalMigrator struct{}
+
+func (testExternalMigrator) Migrate(req MigrateRequest) (MigrateResponse, error) {
+	if !bytes.Contains(req.Src, []byte("listener")) {
+		return MigrateResponse{}, nil
+	}
+
+	return MigrateResponse{
+		Src: append(bytes.Replace(req.Src, []byte(`  listener = "http"`+"\n"), nil, 1), []byte(`
+resource "aws_lb_listener" "test_listener" {
+  load_balancer_arn = aws_lb.test.arn
+  protocol          = "HTTP"
+}
+`)...),
+		Migrations: []PluginMigration{
+			{Address: "aws_lb_listener.test_listener", Parent: "aws_lb.test"},
+		},
+		Warnings: []string{"Unable to migrate default actions of aws_lb.test"},
+	}, nil
+}
+
+func TestPluginMigrator(t *testing.T) {
+	cases := []struct {
+		src            string
+		want           string
+		wantMigrations []string
+	}{
+		{
+			src: `resource "aws_lb" "test" {
+  name     = "test"
+  listener = "http"
+}
+`,
+			want: `resource "aws_lb" "test" {
+  name = "test"
+}
+
+resource "aws_lb_listener" "test_listener" {
+  load_balancer_arn = aws_lb.test.arn
+  protocol          = "HTTP"
+}
+`,
+			wantMigrations: []string{
+				"aws_lb_listener.test_listener,aws_lb.test",
+			},
+		},
+		{
+			src: `resource "aws_lb" "test" {
+  name = "test"
+}
+`,
+			want: `resource "aws_lb" "test" {
+  name = "test"
+}
+`,
+			wantMigrations: nil,
+		},
+	}
+
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		pluginName: &MigratorPlugin{Impl: testExternalMigrator{}},
+	}, nil)
+	defer client.Close()
+
+	raw, err := client.Dispense(pluginName)
+	if err != nil {
+		t.Fatalf("Dispense() returns unexpected err: %s", err)
+	}
+
+	for _, tc := range cases {
+		f, diags := hclwrite.ParseConfig([]byte(tc.src), "test.tf", hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			t.Fatalf("ParseConfig() returns unexpected err: %s", diags)
+		}
+
+		m := &PluginMigrator{
+			path:         "tfrefactor-migrator-aws_lb",
+			migratorType: "resource",
+			resourceType: "aws_lb",
+			external:     raw.(ExternalMigrator),
+		}
+
+		if err := m.Migrate(f); err != nil {
+			t.Fatalf("Migrate() with src = %s returns unexpected err: %s", tc.src, err)
+		}
+
+		if got := string(f.Bytes()); got != tc.want {
+			t.Errorf("Migrate() with src = %s returns %s, but want = %s", tc.src, got, tc.want)
+		}
+
+		if got := m.Migrations(); !reflect.DeepEqual(got, tc.wantMigrations) {
+			t.Errorf("Migrations() with src = %s returns %#v, but want = %#v", tc.src, got, tc.wantMigrations)
+		}
+	}
+}
+
+func TestFindPlugin(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", "")
+
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"aws_lb"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("failed to write plugin: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"aws_instance"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatalf("failed to write plugin: %s", err)
+	}
+
+	cases := []struct {
+		resourceType string
+		want         string
+	}{
+		{
+			resourceType: "aws_lb",
+			want:         filepath.Join(dir, PluginPrefix+"aws_lb"),
+		},
+		{
+			// not executable
+			resourceType: "aws_instance",
+			want:         "",
+		},
+		{
+			resourceType: "aws_vpc",
+			want:         "",
+		},
+	}
+
+	for _, tc := range cases {
+		if got := FindPlugin(tc.resourceType, []string{dir}, false); got != tc.want {
+			t.Errorf("FindPlugin() with resourceType = %s returns %s, but want = %s", tc.resourceType, got, tc.want)
+		}
+
+		if got := strings.HasPrefix(FindPlugin(tc.resourceType, nil, true), dir); got {
+			t.Errorf("FindPlugin() with resourceType = %s and no plugin dirs returns a plugin in %s", tc.resourceType, dir)
+		}
+	}
+
+	// Plugins on PATH are only found if searching PATH is enabled
+	t.Setenv("PATH", dir)
+
+	if got := FindPlugin("aws_lb", nil, false); got != "" {
+		t.Errorf("FindPlugin() without searching PATH returns %s, but want = \"\"", got)
+	}
+
+	if got, want := FindPlugin("aws_lb", nil, true), filepath.Join(dir, PluginPrefix+"aws_lb"); got != want {
+		t.Errorf("FindPlugin() searching PATH returns %s, but want = %s", got, want)
+	}
+}
+
+func TestPluginClientsDispense(t *testing.T) {
+	plugins := newPluginClients()
+	defer plugins.kill()
+
+	// The plugin process of a path is reused for every file once started
+	path := filepath.Join(t.TempDir(), PluginPrefix+"aws_lb")
+	plugins.externals[path] = testExternalMigrator{}
+
+	for i := 0; i < 2; i++ {
+		m := newPluginMigrator(path, "resource", "aws_lb", nil, nil, nil, nil, nil, plugins)
+		f, diags := hclwrite.ParseConfig([]byte(`resource "aws_lb" "test" {
+  name     = "test"
+  listener = "http"
+}
+`), "test.tf", hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			t.Fatalf("failed to parse src: %s", diags)
+		}
+
+		if err := m.Migrate(f); err != nil {
+			t.Fatalf("Migrate() returns unexpected err: %s", err)
+		}
+
+		if want := []string{"aws_lb_listener.test_listener,aws_lb.test"}; !reflect.DeepEqual(m.Migrations(), want) {
+			t.Errorf("Migrations() returns %#v, but want = %#v", m.Migrations(), want)
+		}
+	}
+
+	if _, err := plugins.dispense(filepath.Join(t.TempDir(), PluginPrefix+"aws_vpc")); err == nil {
+		t.Errorf("dispense() of a missing plugin returns no error")
+	}
+}
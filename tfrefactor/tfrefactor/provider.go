@@ -0,0 +1,94 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ProviderAwsConfigurationMigrator migrates the arguments of "aws" provider blocks,
+// including aliased ones, which were renamed or removed in v4.0.0 of the provider.
+type ProviderAwsConfigurationMigrator struct {
+	newResourceNames []string
+}
+
+func NewProviderAwsConfigurationMigrator() (Migrator, error) {
+	return &ProviderAwsConfigurationMigrator{}, nil
+}
+
+func (m *ProviderAwsConfigurationMigrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating provider configurations: empty file")
+	}
+
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		if block.Type() != "provider" || len(labels) != 1 || labels[0] != "aws" {
+			continue
+		}
+
+		body := block.Body()
+		providerPath := "provider.aws"
+		if alias, ok := literalString(body.GetAttribute("alias")); ok {
+			providerPath = fmt.Sprintf("%s.%s", providerPath, alias)
+		}
+
+		// s3_force_path_style is represented as s3_use_path_style
+		if renameAttribute(body, "s3_force_path_style", "s3_use_path_style") != nil {
+			log.Printf("	  ✓ Renamed s3_force_path_style to s3_use_path_style in %s", providerPath)
+		}
+
+		// shared_credentials_file is represented as a list in shared_credentials_files
+		if attr := renameAttribute(body, "shared_credentials_file", "shared_credentials_files"); attr != nil {
+			body.SetAttributeRaw("shared_credentials_files", listTokens(attr.Expr().BuildTokens(nil)))
+			log.Printf("	  ✓ Renamed shared_credentials_file to shared_credentials_files in %s", providerPath)
+		}
+
+		// skip_get_ec2_platforms was removed
+		if body.RemoveAttribute("skip_get_ec2_platforms") != nil {
+			log.Printf("	  ✓ Removed skip_get_ec2_platforms in %s", providerPath)
+		}
+
+		for _, b := range body.Blocks() {
+			if b.Type() != "assume_role" {
+				continue
+			}
+
+			// duration_seconds is represented as a duration string e.g. "3600s" in duration
+			attr := renameAttribute(b.Body(), "duration_seconds", "duration")
+			if attr == nil {
+				continue
+			}
+
+			if v, ok := literalValue(attr); ok && !v.IsNull() && v.Type() == cty.Number {
+				seconds, _ := v.AsBigFloat().Int64()
+				b.Body().SetAttributeValue("duration", cty.StringVal(fmt.Sprintf("%ds", seconds)))
+			} else {
+				tokens := hclwrite.Tokens{
+					{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
+					{Type: hclsyntax.TokenTemplateInterp, Bytes: []byte("${")},
+				}
+				tokens = append(tokens, attr.Expr().BuildTokens(nil)...)
+				tokens = append(tokens, hclwrite.Tokens{
+					{Type: hclsyntax.TokenTemplateSeqEnd, Bytes: []byte("}")},
+					{Type: hclsyntax.TokenQuotedLit, Bytes: []byte("s")},
+					{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+				}...)
+				b.Body().SetAttributeRaw("duration", tokens)
+			}
+			log.Printf("	  ✓ Renamed assume_role.duration_seconds to assume_role.duration in %s", providerPath)
+		}
+	}
+
+	return nil
+}
+
+func (m *ProviderAwsConfigurationMigrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
@@ -0,0 +1,268 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// v5ResourceMigrations maps resource types to the migration of their arguments
+// which were renamed or removed in v5.0.0 of the provider.
+var v5ResourceMigrations = map[string]func(m *ProviderAwsV5Migrator, body *hclwrite.Body, resourcePath string){
+	ResourceTypeAwsAutoscalingAttachment:       (*ProviderAwsV5Migrator).migrateAwsAutoscalingAttachment,
+	ResourceTypeAwsAutoscalingGroup:            (*ProviderAwsV5Migrator).migrateAwsAutoscalingGroup,
+	ResourceTypeAwsDbInstance:                  (*ProviderAwsV5Migrator).migrateAwsDbInstance,
+	ResourceTypeAwsEip:                         (*ProviderAwsV5Migrator).migrateAwsEip,
+	ResourceTypeAwsElasticacheReplicationGroup: (*ProviderAwsV5Migrator).migrateAwsElasticacheReplicationGroup,
+}
+
+// ProviderAwsV5Migrator migrates resource arguments which were renamed or removed
+// in v5.0.0 of the provider. Existing resources are modified in place.
+type ProviderAwsV5Migrator struct {
+	arguments        *ArgumentFilter
+	names            *NameFilter
+	newResourceNames []string
+	// ignore annotation of the block being migrated, if any
+	annotation *ignoreAnnotation
+}
+
+func NewProviderAwsV5Migrator(arguments *ArgumentFilter, names *NameFilter) (Migrator, error) {
+	return &ProviderAwsV5Migrator{
+		arguments: arguments,
+		names:     names,
+	}, nil
+}
+
+func (m *ProviderAwsV5Migrator) SkipResourceName(address string) bool {
+	if m == nil {
+		return false
+	}
+
+	return m.names.Skip(address)
+}
+
+func (m *ProviderAwsV5Migrator) SkipArgument(arg string) bool {
+	if m == nil {
+		return false
+	}
+
+	if m.annotation.skipArgument(arg) {
+		return true
+	}
+
+	return m.arguments.Skip(arg)
+}
+
+func (m *ProviderAwsV5Migrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating resources to v5: empty file")
+	}
+
+	fileAnnotation := fileIgnoreAnnotation(f)
+
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		if block.Type() != "resource" || len(labels) != 2 || m.SkipResourceName(blockAddress(block)) {
+			continue
+		}
+
+		m.annotation = blockIgnoreAnnotation(block, fileAnnotation)
+		if m.annotation.skipResource() {
+			continue
+		}
+
+		migrate, ok := v5ResourceMigrations[labels[0]]
+		if !ok {
+			continue
+		}
+
+		migrate(m, block.Body(), strings.Join(labels, "."))
+	}
+
+	return nil
+}
+
+func (m *ProviderAwsV5Migrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
+
+// renameAttribute renames an attribute unless it is skipped, logging a warning if it cannot be renamed
+// because an attribute with the new name already exists.
+func (m *ProviderAwsV5Migrator) renameAttribute(body *hclwrite.Body, name, newName, resourcePath string) *hclwrite.Attribute {
+	if body.GetAttribute(name) == nil || m.SkipArgument(name) {
+		return nil
+	}
+
+	attr := renameAttribute(body, name, newName)
+	if attr == nil {
+		log.Printf("[WARN] Unable to rename %s to %s in %s: %s already exists", name, newName, resourcePath, newName)
+		return nil
+	}
+
+	log.Printf("	  ✓ Renamed %s to %s in %s", name, newName, resourcePath)
+
+	return attr
+}
+
+func (m *ProviderAwsV5Migrator) migrateAwsAutoscalingAttachment(body *hclwrite.Body, resourcePath string) {
+	m.renameAttribute(body, "alb_target_group_arn", "lb_target_group_arn", resourcePath)
+}
+
+func (m *ProviderAwsV5Migrator) migrateAwsAutoscalingGroup(body *hclwrite.Body, resourcePath string) {
+	tags := body.GetAttribute("tags")
+	if tags == nil || m.SkipArgument("tags") {
+		return
+	}
+
+	elems, ok := objectListItems(tags)
+	if !ok {
+		// Tags given as an expression e.g. var.tags are represented as a dynamic "tag" block
+		dynamicBlock := body.AppendNewBlock("dynamic", []string{"tag"})
+		dynamicBlock.Body().SetAttributeRaw("for_each", tags.Expr().BuildTokens(nil))
+		dynamicBlock.Body().AppendNewline()
+
+		contentBlock := dynamicBlock.Body().AppendNewBlock("content", nil)
+		for _, k := range autoscalingGroupTagKeys {
+			contentBlock.Body().SetAttributeTraversal(k, hcl.Traversal{
+				hcl.TraverseRoot{
+					Name: fmt.Sprintf("tag.value.%s", k),
+				},
+			})
+		}
+
+		body.RemoveAttribute("tags")
+		log.Printf("	  ✓ Migrated tags to a dynamic tag block in %s", resourcePath)
+		return
+	}
+
+	for _, items := range elems {
+		values := make(map[string]string, len(items))
+		for _, item := range items {
+			values[item.Key] = item.Value
+		}
+
+		tagBlock := body.AppendNewBlock("tag", nil)
+		for _, k := range autoscalingGroupTagKeys {
+			v, ok := values[k]
+			if !ok {
+				continue
+			}
+
+			// propagate_at_launch is commonly given as a string e.g. "true"
+			if k == "propagate_at_launch" {
+				if unquoted := strings.Trim(v, `"`); unquoted == "true" || unquoted == "false" {
+					v = unquoted
+				}
+			}
+
+			tagBlock.Body().SetAttributeTraversal(k, hcl.Traversal{
+				hcl.TraverseRoot{
+					Name: v,
+				},
+			})
+		}
+
+		for k := range values {
+			if !isAutoscalingGroupTagKey(k) {
+				log.Printf("[WARN] Unable to migrate tags.%s in %s: unsupported by tag blocks", k, resourcePath)
+			}
+		}
+	}
+
+	body.RemoveAttribute("tags")
+	log.Printf("	  ✓ Migrated tags to tag blocks in %s", resourcePath)
+}
+
+// autoscalingGroupTagKeys are the arguments of an aws_autoscaling_group tag block.
+var autoscalingGroupTagKeys = []string{"key", "value", "propagate_at_launch"}
+
+func isAutoscalingGroupTagKey(k string) bool {
+	for _, key := range autoscalingGroupTagKeys {
+		if key == k {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *ProviderAwsV5Migrator) migrateAwsDbInstance(body *hclwrite.Body, resourcePath string) {
+	m.renameAttribute(body, "name", "db_name", resourcePath)
+}
+
+func (m *ProviderAwsV5Migrator) migrateAwsEip(body *hclwrite.Body, resourcePath string) {
+	attr := body.GetAttribute("vpc")
+	if attr == nil || m.SkipArgument("vpc") {
+		return
+	}
+
+	if body.GetAttribute("domain") != nil {
+		log.Printf("[WARN] Unable to migrate vpc to domain in %s: domain already exists", resourcePath)
+		return
+	}
+
+	v, ok := literalValue(attr)
+	if ok && !v.IsNull() && v.Type() == cty.Bool {
+		if v.False() {
+			body.RemoveAttribute("vpc")
+			log.Printf("	  ✓ Removed vpc in %s", resourcePath)
+			return
+		}
+
+		renameAttribute(body, "vpc", "domain")
+		body.SetAttributeValue("domain", cty.StringVal("vpc"))
+		log.Printf("	  ✓ Migrated vpc to domain in %s", resourcePath)
+		return
+	}
+
+	// vpc is represented as a conditional on the original expression
+	tokens := attr.Expr().BuildTokens(nil)
+	renameAttribute(body, "vpc", "domain")
+	tokens = append(tokens, hclwrite.Tokens{
+		{Type: hclsyntax.TokenQuestion, Bytes: []byte("?")},
+		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
+		{Type: hclsyntax.TokenQuotedLit, Bytes: []byte("vpc")},
+		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+		{Type: hclsyntax.TokenColon, Bytes: []byte(":")},
+		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
+		{Type: hclsyntax.TokenQuotedLit, Bytes: []byte("standard")},
+		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+	}...)
+	body.SetAttributeRaw("domain", tokens)
+	log.Printf("	  ✓ Migrated vpc to domain in %s", resourcePath)
+}
+
+func (m *ProviderAwsV5Migrator) migrateAwsElasticacheReplicationGroup(body *hclwrite.Body, resourcePath string) {
+	m.renameAttribute(body, "replication_group_description", "description", resourcePath)
+	m.renameAttribute(body, "number_cache_clusters", "num_cache_clusters", resourcePath)
+
+	// cluster_mode arguments are represented as top-level arguments
+	clusterMode := body.FirstMatchingBlock("cluster_mode", nil)
+	if clusterMode == nil || m.SkipArgument("cluster_mode") {
+		return
+	}
+
+	for _, name := range []string{"num_node_groups", "replicas_per_node_group"} {
+		if clusterMode.Body().GetAttribute(name) != nil && body.GetAttribute(name) != nil {
+			log.Printf("[WARN] Unable to migrate cluster_mode.%s in %s: %s already exists", name, resourcePath, name)
+			return
+		}
+	}
+
+	for _, name := range []string{"num_node_groups", "replicas_per_node_group"} {
+		if attr := clusterMode.Body().GetAttribute(name); attr != nil {
+			body.SetAttributeRaw(name, attr.Expr().BuildTokens(nil))
+		}
+	}
+
+	body.RemoveBlock(clusterMode)
+	log.Printf("	  ✓ Migrated cluster_mode to num_node_groups and replicas_per_node_group in %s", resourcePath)
+}
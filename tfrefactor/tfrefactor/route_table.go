@@ -0,0 +1,171 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// routeDestinations maps the destination arguments of inline routes to the arguments of aws_route.
+var routeDestinations = []struct {
+	Name    string
+	NewName string
+}{
+	{Name: "cidr_block", NewName: "destination_cidr_block"},
+	{Name: "ipv6_cidr_block", NewName: "destination_ipv6_cidr_block"},
+	{Name: "destination_prefix_list_id", NewName: "destination_prefix_list_id"},
+}
+
+// ProviderAwsRouteTableMigrator migrates the inline "route" blocks of aws_route_table resources
+// to aws_route resources.
+type ProviderAwsRouteTableMigrator struct {
+	arguments        *ArgumentFilter
+	names            *NameFilter
+	newResourceNames []string
+
+	// ignore annotation of the block being migrated, if any
+	annotation *ignoreAnnotation
+}
+
+func NewProviderAwsRouteTableMigrator(arguments *ArgumentFilter, names *NameFilter) (Migrator, error) {
+	return &ProviderAwsRouteTableMigrator{
+		arguments: arguments,
+		names:     names,
+	}, nil
+}
+
+func (m *ProviderAwsRouteTableMigrator) SkipResourceName(address string) bool {
+	if m == nil {
+		return false
+	}
+
+	return m.names.Skip(address)
+}
+
+func (m *ProviderAwsRouteTableMigrator) SkipArgument(arg string) bool {
+	if m == nil {
+		return false
+	}
+
+	if m.annotation.skipArgument(arg) {
+		return true
+	}
+
+	return m.arguments.Skip(arg)
+}
+
+func (m *ProviderAwsRouteTableMigrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating (%s) resources: empty file", ResourceTypeAwsRouteTable)
+	}
+
+	fileAnnotation := fileIgnoreAnnotation(f)
+
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		if block.Type() != "resource" || len(labels) != 2 || labels[0] != ResourceTypeAwsRouteTable {
+			continue
+		}
+
+		m.annotation = blockIgnoreAnnotation(block, fileAnnotation)
+		if m.SkipResourceName(blockAddress(block)) || m.annotation.skipResource() || m.SkipArgument(Route) {
+			continue
+		}
+
+		routeTablePath := strings.Join(labels, ".")
+		log.Printf("[INFO] Found %s\n", routeTablePath)
+
+		// Special Attribute Handling i.e. for_each and count
+		hasInstances := block.Body().GetAttribute("count") != nil || block.Body().GetAttribute("for_each") != nil
+
+		var i int
+		for _, route := range block.Body().Blocks() {
+			if route.Type() == "dynamic" && len(route.Labels()) == 1 && route.Labels()[0] == Route {
+				log.Printf("[WARN] Unable to migrate dynamic %s blocks in %s", Route, routeTablePath)
+				continue
+			}
+
+			if route.Type() != Route {
+				continue
+			}
+
+			block.Body().RemoveBlock(route)
+			f.Body().AppendNewline()
+
+			// Routes are named after their destination, which is also part of the import ID
+			// e.g. rtb-656C65616E6F72_10.42.0.0/16
+			suffix := fmt.Sprintf("%s_%d", Route, i)
+			i++
+			var importID string
+			for _, d := range routeDestinations {
+				if v, ok := literalString(route.Body().GetAttribute(d.Name)); ok && v != "" {
+					suffix = resourceNameSuffix(v)
+					if d.Name == "ipv6_cidr_block" {
+						suffix = fmt.Sprintf("ipv6_%s", suffix)
+					}
+					importID = fmt.Sprintf("{id}_%s", v)
+					break
+				}
+			}
+
+			newlabels := []string{ResourceTypeAwsRoute, fmt.Sprintf("%s_%s", labels[1], suffix)}
+			newBlock := f.Body().AppendNewBlock(block.Type(), newlabels)
+
+			newBlock.Body().SetAttributeTraversal("route_table_id", hcl.Traversal{
+				hcl.TraverseRoot{
+					Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
+				},
+			})
+
+			for _, d := range routeDestinations {
+				if attr := route.Body().GetAttribute(d.Name); attr != nil {
+					newBlock.Body().SetAttributeRaw(d.NewName, attr.Expr().BuildTokens(nil))
+				}
+			}
+
+			// Expected: a target e.g. gateway_id, nat_gateway_id, transit_gateway_id
+			for _, k := range sortedAttributeNames(route.Body()) {
+				if isRouteDestination(k) {
+					continue
+				}
+				newBlock.Body().SetAttributeRaw(k, route.Body().GetAttribute(k).Expr().BuildTokens(nil))
+			}
+
+			if hasInstances {
+				appendTodoComment(newBlock.Body(), fmt.Sprintf("Replace 'route_table_id' argument value with correct instance index e.g. %s.%s[count.index].id", labels[0], labels[1]))
+			}
+
+			log.Printf("	  ✓ Created %s.%s", ResourceTypeAwsRoute, newlabels[1])
+
+			if importID == "" {
+				// The route table ID alone is not the import ID of a route, so the route is not included in the migrations
+				log.Printf("[WARN] Unable to determine import ID of %s.%s: destination cannot be determined statically; it must be imported manually", ResourceTypeAwsRoute, newlabels[1])
+				continue
+			}
+
+			m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s,%s", ResourceTypeAwsRoute, newlabels[1], routeTablePath, importID))
+		}
+	}
+
+	return nil
+}
+
+func (m *ProviderAwsRouteTableMigrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
+
+func isRouteDestination(k string) bool {
+	for _, d := range routeDestinations {
+		if d.Name == k {
+			return true
+		}
+	}
+
+	return false
+}
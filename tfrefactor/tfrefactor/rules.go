@@ -0,0 +1,395 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Rules is a set of declarative migrations read from a rules file e.g.
+//
+//	resource "aws_s3_bucket" {
+//	  migrate "aws_s3_bucket_versioning" {
+//	    suffix          = "versioning"
+//	    parent_argument = "bucket"
+//
+//	    block "versioning" {
+//	      target = "versioning_configuration"
+//
+//	      argument "enabled" {
+//	        target = "status"
+//	        values = { "true" = "Enabled", "false" = "Suspended" }
+//	      }
+//	    }
+//	  }
+//	}
+type Rules struct {
+	Resources []*ResourceRules `hcl:"resource,block"`
+}
+
+// ResourceRules are the migrations of a resource type.
+type ResourceRules struct {
+	Type       string           `hcl:"type,label"`
+	Migrations []*MigrationRule `hcl:"migrate,block"`
+}
+
+// MigrationRule moves arguments and blocks of a resource into a new resource of the given type,
+// named after the resource with the given suffix e.g. example_versioning.
+type MigrationRule struct {
+	ResourceType string `hcl:"resource_type,label"`
+	Suffix       string `hcl:"suffix"`
+
+	// The argument of the new resource referencing the resource e.g. bucket
+	ParentArgument string `hcl:"parent_argument"`
+
+	// The attribute of the resource referenced by the new resource (default: id)
+	ParentAttribute string `hcl:"parent_attribute,optional"`
+
+	// An import ID template in which {id} is the ID of the resource (default: {id})
+	ImportID string `hcl:"import_id,optional"`
+
+	Arguments []*ArgumentRule `hcl:"argument,block"`
+	Blocks    []*BlockRule    `hcl:"block,block"`
+}
+
+// ArgumentRule moves an argument, optionally renamed to target and with its literal values mapped.
+type ArgumentRule struct {
+	Name   string         `hcl:"name,label"`
+	Target string         `hcl:"target,optional"`
+	Values hcl.Expression `hcl:"values,optional"`
+
+	// mapped values keyed by the string representation of the original literal value
+	mapping map[string]cty.Value
+}
+
+// BlockRule moves a block, optionally renamed to target. Arguments and nested blocks
+// without a rule are moved as-is.
+type BlockRule struct {
+	Name      string          `hcl:"name,label"`
+	Target    string          `hcl:"target,optional"`
+	Arguments []*ArgumentRule `hcl:"argument,block"`
+	Blocks    []*BlockRule    `hcl:"block,block"`
+}
+
+// LoadRules reads rules from a rules file.
+func LoadRules(fs afero.Fs, filename string) (*Rules, error) {
+	src, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %s", err)
+	}
+
+	return ParseRules(src, filename)
+}
+
+// ParseRules parses rules from the HCL source of a rules file.
+func ParseRules(src []byte, filename string) (*Rules, error) {
+	f, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse rules file: %s", diags)
+	}
+
+	rules := &Rules{}
+	if diags := gohcl.DecodeBody(f.Body, nil, rules); diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode rules file: %s", diags)
+	}
+
+	for _, r := range rules.Resources {
+		for _, mr := range r.Migrations {
+			if mr.ParentAttribute == "" {
+				mr.ParentAttribute = "id"
+			}
+			if err := decodeArgumentRuleValues(mr.Arguments); err != nil {
+				return nil, err
+			}
+			if err := decodeBlockRuleValues(mr.Blocks); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// Resource returns the rules of a resource type, or nil if there are none.
+func (r *Rules) Resource(resourceType string) *ResourceRules {
+	if r == nil {
+		return nil
+	}
+
+	for _, rr := range r.Resources {
+		if rr.Type == resourceType {
+			return rr
+		}
+	}
+
+	return nil
+}
+
+func decodeBlockRuleValues(rules []*BlockRule) error {
+	for _, br := range rules {
+		if err := decodeArgumentRuleValues(br.Arguments); err != nil {
+			return err
+		}
+		if err := decodeBlockRuleValues(br.Blocks); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeArgumentRuleValues(rules []*ArgumentRule) error {
+	for _, ar := range rules {
+		if ar.Values == nil {
+			continue
+		}
+
+		v, diags := ar.Values.Value(nil)
+		if diags.HasErrors() {
+			return fmt.Errorf("failed to decode values of argument %q: %s", ar.Name, diags)
+		}
+
+		if v.IsNull() {
+			continue
+		}
+
+		if !v.Type().IsObjectType() && !v.Type().IsMapType() {
+			return fmt.Errorf("failed to decode values of argument %q: must be an object", ar.Name)
+		}
+
+		ar.mapping = v.AsValueMap()
+	}
+
+	return nil
+}
+
+// targetName returns the name of an argument or block after migration.
+func targetName(name, target string) string {
+	if target == "" {
+		return name
+	}
+	return target
+}
+
+// RulesMigrator migrates resources of a type according to declarative rules.
+type RulesMigrator struct {
+	rules            *ResourceRules
+	arguments        *ArgumentFilter
+	names            *NameFilter
+	newResourceNames []string
+
+	// ignore annotation of the block being migrated, if any
+	annotation *ignoreAnnotation
+}
+
+func NewRulesMigrator(rules *Rules, resourceType string, arguments *ArgumentFilter, names *NameFilter) (Migrator, error) {
+	rr := rules.Resource(resourceType)
+	if rr == nil {
+		return nil, fmt.Errorf("failed to create new rules migrator. no rules for resource type: %s", resourceType)
+	}
+
+	return newRulesMigrator(rr, arguments, names), nil
+}
+
+func newRulesMigrator(rules *ResourceRules, arguments *ArgumentFilter, names *NameFilter) *RulesMigrator {
+	return &RulesMigrator{
+		rules:     rules,
+		arguments: arguments,
+		names:     names,
+	}
+}
+
+func (m *RulesMigrator) SkipResourceName(address string) bool {
+	if m == nil {
+		return false
+	}
+
+	return m.names.Skip(address)
+}
+
+func (m *RulesMigrator) SkipArgument(arg string) bool {
+	if m == nil {
+		return false
+	}
+
+	if m.annotation.skipArgument(arg) {
+		return true
+	}
+
+	return m.arguments.Skip(arg)
+}
+
+func (m *RulesMigrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating (%s) resources: empty file", m.rules.Type)
+	}
+
+	fileAnnotation := fileIgnoreAnnotation(f)
+
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		if block.Type() != "resource" || len(labels) != 2 || labels[0] != m.rules.Type {
+			continue
+		}
+
+		m.annotation = blockIgnoreAnnotation(block, fileAnnotation)
+		if m.SkipResourceName(blockAddress(block)) || m.annotation.skipResource() {
+			continue
+		}
+
+		log.Printf("[INFO] Found %s\n", strings.Join(labels, "."))
+
+		m.migrateResource(f, block)
+	}
+
+	return nil
+}
+
+func (m *RulesMigrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
+
+// migrateResource applies each migration rule to a resource block,
+// creating a new resource if any of its arguments or blocks are present.
+func (m *RulesMigrator) migrateResource(f *hclwrite.File, block *hclwrite.Block) {
+	labels := block.Labels()
+	resourcePath := strings.Join(labels, ".")
+	hasInstances := block.Body().GetAttribute("count") != nil || block.Body().GetAttribute("for_each") != nil
+
+	for _, rule := range m.rules.Migrations {
+		var arguments []*ArgumentRule
+		for _, ar := range rule.Arguments {
+			if !m.SkipArgument(ar.Name) && block.Body().GetAttribute(ar.Name) != nil {
+				arguments = append(arguments, ar)
+			}
+		}
+
+		var blocks []*hclwrite.Block
+		var blockRules []*BlockRule
+		for _, b := range block.Body().Blocks() {
+			if m.SkipArgument(b.Type()) {
+				continue
+			}
+			for _, br := range rule.Blocks {
+				if br.Name == b.Type() {
+					blocks = append(blocks, b)
+					blockRules = append(blockRules, br)
+				}
+			}
+		}
+
+		if len(arguments) == 0 && len(blocks) == 0 {
+			continue
+		}
+
+		f.Body().AppendNewline()
+
+		newlabels := []string{rule.ResourceType, fmt.Sprintf("%s_%s", labels[1], rule.Suffix)}
+		newBlock := f.Body().AppendNewBlock(block.Type(), newlabels)
+
+		newBlock.Body().SetAttributeTraversal(rule.ParentArgument, hcl.Traversal{
+			hcl.TraverseRoot{
+				Name: fmt.Sprintf("%s.%s.%s", labels[0], labels[1], rule.ParentAttribute),
+			},
+		})
+
+		for _, ar := range arguments {
+			attr := block.Body().GetAttribute(ar.Name)
+			block.Body().RemoveAttribute(ar.Name)
+
+			name := targetName(ar.Name, ar.Target)
+			newBlock.Body().SetAttributeRaw(name, attr.Expr().BuildTokens(nil))
+			mapArgumentValue(newBlock.Body(), name, ar, newlabels)
+		}
+
+		for i, b := range blocks {
+			block.Body().RemoveBlock(b)
+			migrateBlock(b, blockRules[i], newlabels)
+			newBlock.Body().AppendBlock(b)
+		}
+
+		if hasInstances {
+			appendTodoComment(newBlock.Body(), fmt.Sprintf("Replace '%s' argument value with correct instance index e.g. %s.%s[count.index].%s", rule.ParentArgument, labels[0], labels[1], rule.ParentAttribute))
+		}
+
+		log.Printf("	  ✓ Created %s.%s", rule.ResourceType, newlabels[1])
+
+		if rule.ImportID == "" {
+			m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s", rule.ResourceType, newlabels[1], resourcePath))
+			continue
+		}
+
+		m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s,%s", rule.ResourceType, newlabels[1], resourcePath, rule.ImportID))
+	}
+}
+
+// migrateBlock renames a block and its arguments and nested blocks in place.
+func migrateBlock(b *hclwrite.Block, rule *BlockRule, labels []string) {
+	b.SetType(targetName(rule.Name, rule.Target))
+
+	for _, ar := range rule.Arguments {
+		name := targetName(ar.Name, ar.Target)
+		if name != ar.Name && renameAttribute(b.Body(), ar.Name, name) == nil {
+			continue
+		}
+		mapArgumentValue(b.Body(), name, ar, labels)
+	}
+
+	for _, nested := range b.Body().Blocks() {
+		for _, br := range rule.Blocks {
+			if br.Name == nested.Type() {
+				migrateBlock(nested, br, labels)
+			}
+		}
+	}
+}
+
+// mapArgumentValue replaces the literal value of an argument with its mapped value, if any.
+func mapArgumentValue(body *hclwrite.Body, name string, rule *ArgumentRule, labels []string) {
+	attr := body.GetAttribute(name)
+	if attr == nil || len(rule.mapping) == 0 {
+		return
+	}
+
+	v, ok := literalValue(attr)
+	if !ok {
+		log.Printf("[WARN] Unable to map the value of '%s' in %s: value cannot be determined statically", name, strings.Join(labels, "."))
+		appendTodoComment(body, fmt.Sprintf("Ensure the value of '%s' is valid for %s", name, labels[0]))
+		return
+	}
+
+	if mapped, ok := rule.mapping[literalKey(v)]; ok {
+		body.SetAttributeValue(name, mapped)
+	}
+}
+
+// literalKey returns the string representation of a literal value used as a key of a values mapping.
+func literalKey(v cty.Value) string {
+	if v.IsNull() {
+		return "null"
+	}
+
+	switch v.Type() {
+	case cty.String:
+		return v.AsString()
+	case cty.Bool:
+		if v.True() {
+			return "true"
+		}
+		return "false"
+	case cty.Number:
+		return v.AsBigFloat().Text('f', -1)
+	}
+
+	return ""
+}
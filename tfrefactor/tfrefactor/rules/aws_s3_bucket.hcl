@@ -0,0 +1,29 @@
+# Arguments of the aws_s3_bucket resource which are represented as
+# independent resources since v4.0.0 of the provider.
+
+resource "aws_s3_bucket" {
+  migrate "aws_s3_bucket_accelerate_configuration" {
+    suffix          = "accelerate_configuration"
+    parent_argument = "bucket"
+
+    argument "acceleration_status" {
+      target = "status"
+    }
+  }
+
+  migrate "aws_s3_bucket_policy" {
+    suffix          = "policy"
+    parent_argument = "bucket"
+
+    argument "policy" {}
+  }
+
+  migrate "aws_s3_bucket_request_payment_configuration" {
+    suffix          = "request_payment_configuration"
+    parent_argument = "bucket"
+
+    argument "request_payer" {
+      target = "payer"
+    }
+  }
+}
@@ -0,0 +1,173 @@
+package tfrefactor
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+func TestRulesMigrator(t *testing.T) {
+	rules, err := ParseRules([]byte(`
+resource "example_widget" {
+  migrate "example_widget_settings" {
+    suffix           = "settings"
+    parent_argument  = "widget_name"
+    parent_attribute = "name"
+    import_id        = "{id}/settings"
+
+    argument "mode" {
+      values = { fast = "FAST" }
+    }
+
+    argument "size" {
+      target = "capacity"
+    }
+  }
+
+  migrate "example_widget_schedule" {
+    suffix          = "schedule"
+    parent_argument = "widget_id"
+
+    block "schedule" {
+      target = "schedule_configuration"
+
+      argument "enabled" {
+        target = "status"
+        values = {
+          "true"  = "Enabled"
+          "false" = "Disabled"
+        }
+      }
+    }
+  }
+}
+`), "rules.hcl")
+	if err != nil {
+		t.Fatalf("ParseRules() returns unexpected err: %s", err)
+	}
+
+	cases := []struct {
+		src            string
+		want           string
+		wantMigrations []string
+	}{
+		{
+			src: `
+resource "example_widget" "test" {
+  name = "test"
+  mode = "fast"
+  size = var.size
+
+  schedule {
+    enabled    = true
+    expression = "rate(1 day)"
+  }
+}
+
+resource "example_widget" "unchanged" {
+  name = "unchanged"
+}
+`,
+			want: `
+resource "example_widget" "test" {
+  name = "test"
+
+}
+
+resource "example_widget" "unchanged" {
+  name = "unchanged"
+}
+
+resource "example_widget_settings" "test_settings" {
+  widget_name = example_widget.test.name
+  mode        = "FAST"
+  capacity    = var.size
+}
+
+resource "example_widget_schedule" "test_schedule" {
+  widget_id = example_widget.test.id
+  schedule_configuration {
+    status     = "Enabled"
+    expression = "rate(1 day)"
+  }
+}
+`,
+			wantMigrations: []string{
+				"example_widget_settings.test_settings,example_widget.test,{id}/settings",
+				"example_widget_schedule.test_schedule,example_widget.test",
+			},
+		},
+		{
+			src: `
+resource "example_widget" "test" {
+  name = "test"
+
+  schedule {
+    enabled = var.enabled
+  }
+}
+`,
+			want: `
+resource "example_widget" "test" {
+  name = "test"
+
+}
+
+resource "example_widget_schedule" "test_schedule" {
+  widget_id = example_widget.test.id
+  schedule_configuration {
+    status = var.enabled
+    # TODO: Ensure the value of 'status' is valid for example_widget_schedule
+  }
+}
+`,
+			wantMigrations: []string{
+				"example_widget_schedule.test_schedule,example_widget.test",
+			},
+		},
+		{
+			src: `
+resource "example_widget" "test" {
+  count = 2
+  name  = "test-${count.index}"
+  size  = 1
+}
+`,
+			want: `
+resource "example_widget" "test" {
+  count = 2
+  name  = "test-${count.index}"
+}
+
+resource "example_widget_settings" "test_settings" {
+  widget_name = example_widget.test.name
+  capacity    = 1
+  # TODO: Replace 'widget_name' argument value with correct instance index e.g. example_widget.test[count.index].name
+}
+`,
+			wantMigrations: []string{
+				"example_widget_settings.test_settings,example_widget.test,{id}/settings",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		w := &bytes.Buffer{}
+		o := Option{MigratorType: "resource", ResourceType: "example_widget", Rules: rules}
+		migrations, err := MigrateHCL(strings.NewReader(tc.src), w, "test.tf", o)
+		if err != nil {
+			t.Fatalf("MigrateHCL() with src = %s returns unexpected err: %s", tc.src, err)
+		}
+
+		if got := string(hclwrite.Format(w.Bytes())); got != tc.want {
+			t.Errorf("MigrateHCL() with src = %s returns %s, but want = %s", tc.src, got, tc.want)
+		}
+
+		if !reflect.DeepEqual(migrations, tc.wantMigrations) {
+			t.Errorf("MigrateHCL() with src = %s returns migrations %#v, but want = %#v", tc.src, migrations, tc.wantMigrations)
+		}
+	}
+}
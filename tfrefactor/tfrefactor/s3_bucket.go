@@ -1,11 +1,14 @@
 package tfrefactor
 
 import (
+	_ "embed"
 	"encoding/json"
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"log"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -15,35 +18,48 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// awsS3BucketRules are the built-in rules of the aws_s3_bucket resource.
+//
+//go:embed rules/aws_s3_bucket.hcl
+var awsS3BucketRules []byte
+
 type ProviderAwsS3BucketMigrator struct {
-	ignoreArguments     []string
-	ignoreResourceNames []string
-	newResourceNames    []string
+	arguments        *ArgumentFilter
+	names            *NameFilter
+	newResourceNames []string
+	module           *Module
+
+	// migrates the arguments described by the built-in rules
+	rules *RulesMigrator
+
+	// If true, generates ownership controls and a public access block alongside non-private ACLs
+	ownershipControls bool
+
+	// ignore annotation of the block being migrated, if any
+	annotation *ignoreAnnotation
 }
 
-func NewProviderAwsS3BucketMigrator(ignoreArguments, ignoreResourceNames []string) (Migrator, error) {
+func NewProviderAwsS3BucketMigrator(arguments *ArgumentFilter, names *NameFilter, ownershipControls bool, module *Module) (Migrator, error) {
+	rules, err := ParseRules(awsS3BucketRules, "rules/aws_s3_bucket.hcl")
+	if err != nil {
+		return nil, err
+	}
+
 	return &ProviderAwsS3BucketMigrator{
-		ignoreArguments:     ignoreArguments,
-		ignoreResourceNames: ignoreResourceNames,
+		arguments:         arguments,
+		names:             names,
+		module:            module,
+		ownershipControls: ownershipControls,
+		rules:             newRulesMigrator(rules.Resource(ResourceTypeAwsS3Bucket), arguments, names),
 	}, nil
 }
 
-func (m *ProviderAwsS3BucketMigrator) SkipResourceName(resourceName string) bool {
+func (m *ProviderAwsS3BucketMigrator) SkipResourceName(address string) bool {
 	if m == nil {
 		return false
 	}
 
-	if len(m.ignoreResourceNames) == 0 {
-		return false
-	}
-
-	for _, rn := range m.ignoreResourceNames {
-		if rn == resourceName {
-			return true
-		}
-	}
-
-	return false
+	return m.names.Skip(address)
 }
 
 func (m *ProviderAwsS3BucketMigrator) SkipArgument(arg string) bool {
@@ -51,17 +67,11 @@ func (m *ProviderAwsS3BucketMigrator) SkipArgument(arg string) bool {
 		return false
 	}
 
-	if len(m.ignoreArguments) == 0 {
-		return false
-	}
-
-	for _, argument := range m.ignoreArguments {
-		if argument == arg {
-			return true
-		}
+	if m.annotation.skipArgument(arg) {
+		return true
 	}
 
-	return false
+	return m.arguments.Skip(arg)
 }
 
 func (m *ProviderAwsS3BucketMigrator) Migrate(f *hclwrite.File) error {
@@ -84,6 +94,8 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 		return fmt.Errorf("error migrating (%s) resources: empty file", ResourceTypeAwsS3Bucket)
 	}
 
+	fileAnnotation := fileIgnoreAnnotation(f)
+
 	for _, block := range f.Body().Blocks() {
 		if block == nil {
 			continue
@@ -94,7 +106,8 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 			continue
 		}
 
-		if m.SkipResourceName(labels[1]) {
+		m.annotation = blockIgnoreAnnotation(block, fileAnnotation)
+		if m.SkipResourceName(blockAddress(block)) || m.annotation.skipResource() {
 			continue
 		}
 
@@ -105,71 +118,38 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 		countAttr := block.Body().GetAttribute("count")
 		forEachAttr := block.Body().GetAttribute("for_each")
 
+		// Arguments described by the built-in rules e.g. acceleration_status, policy and request_payer
+		n := len(m.rules.newResourceNames)
+		m.rules.annotation = m.annotation
+		m.rules.migrateResource(f, block)
+		m.newResourceNames = append(m.newResourceNames, m.rules.newResourceNames[n:]...)
+
 		/////////////////////////////////////////// Attribute Handling /////////////////////////////////////////////////
-		// 1. acceleration_status
-		// 2. acl
-		// 3. policy
-		// 4. request_payer
+		// 1. acl
 		////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 		var aclResourceBlock *hclwrite.Block
+		var aclAttr *hclwrite.Attribute
 
-		for k, v := range block.Body().Attributes() {
-			if m.SkipArgument(k) {
-				continue
-			}
-			switch k {
-			case AccelerationStatus:
-				block.Body().RemoveAttribute(k)
-				f.Body().AppendNewline()
-
-				newlabels := []string{ResourceMap[k], fmt.Sprintf("%s_%s", labels[1], AccelerateConfiguration)}
-				newBlock := f.Body().AppendNewBlock(block.Type(), newlabels)
-
-				newBlock.Body().SetAttributeTraversal("bucket", hcl.Traversal{
-					hcl.TraverseRoot{
-						Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
-					},
-				})
-
-				newBlock.Body().SetAttributeRaw("status", v.Expr().BuildTokens(nil))
-
-				log.Printf("	  ✓ Created %s.%s", ResourceMap[k], newlabels[1])
-				m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s", ResourceMap[k], newlabels[1], bucketPath))
-			case Acl, Policy:
-				block.Body().RemoveAttribute(k)
-				f.Body().AppendNewline()
-
-				newlabels := []string{ResourceMap[k], fmt.Sprintf("%s_%s", labels[1], k)}
-				aclResourceBlock = f.Body().AppendNewBlock(block.Type(), newlabels)
-
-				aclResourceBlock.Body().SetAttributeTraversal("bucket", hcl.Traversal{
-					hcl.TraverseRoot{
-						Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
-					},
-				})
-
-				aclResourceBlock.Body().SetAttributeRaw(k, v.Expr().BuildTokens(nil))
+		if v := block.Body().GetAttribute(Acl); v != nil && !m.SkipArgument(Acl) {
+			block.Body().RemoveAttribute(Acl)
+			f.Body().AppendNewline()
 
-				log.Printf("	  ✓ Created %s.%s", ResourceMap[k], newlabels[1])
-				m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s", ResourceMap[k], newlabels[1], bucketPath))
-			case RequestPayer:
-				block.Body().RemoveAttribute(k)
-				f.Body().AppendNewline()
+			newlabels := []string{ResourceTypeAwsS3BucketAcl.String(), fmt.Sprintf("%s_%s", labels[1], Acl)}
+			newBlock := f.Body().AppendNewBlock(block.Type(), newlabels)
 
-				newlabels := []string{ResourceMap[k], fmt.Sprintf("%s_%s", labels[1], RequestPaymentConfiguration)}
-				newBlock := f.Body().AppendNewBlock(block.Type(), newlabels)
+			newBlock.Body().SetAttributeTraversal("bucket", hcl.Traversal{
+				hcl.TraverseRoot{
+					Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
+				},
+			})
 
-				newBlock.Body().SetAttributeTraversal("bucket", hcl.Traversal{
-					hcl.TraverseRoot{
-						Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
-					},
-				})
+			newBlock.Body().SetAttributeRaw(Acl, v.Expr().BuildTokens(nil))
 
-				newBlock.Body().SetAttributeRaw("payer", v.Expr().BuildTokens(nil))
+			aclResourceBlock = newBlock
+			aclAttr = v
 
-				log.Printf("	  ✓ Created %s.%s", ResourceMap[k], newlabels[1])
-				m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s", ResourceMap[k], newlabels[1], bucketPath))
-			}
+			log.Printf("	  ✓ Created %s.%s", newlabels[0], newlabels[1])
+			m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s", newlabels[0], newlabels[1], bucketPath))
 		}
 
 		///////////////////////////////////////////// Block Handling ///////////////////////////////////////////////////
@@ -193,8 +173,18 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 		var website *hclwrite.Block
 		var versioning *hclwrite.Block
 
+		// The address of the aws_s3_bucket_versioning resource, if created,
+		// as replication requires versioning to be enabled first
+		var versioningResourcePath string
+
 		for _, subBlock := range block.Body().Blocks() {
-			if m.SkipArgument(subBlock.Type()) {
+			// Dynamic blocks are skipped by the argument they generate e.g. "website"
+			arg := subBlock.Type()
+			if arg == "dynamic" && len(subBlock.Labels()) == 1 {
+				arg = subBlock.Labels()[0]
+			}
+
+			if m.SkipArgument(arg) {
 				continue
 			}
 
@@ -331,53 +321,141 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 				acpBlock := newBlock.Body().AppendNewBlock("access_control_policy", nil)
 
 				for _, grant := range grants {
-					grantBlock := acpBlock.Body().AppendNewBlock("grant", nil)
-					grantee := grantBlock.Body().AppendNewBlock("grantee", nil)
+					// Expected: id, type, uri, permissions
+					permissionsAttr := grant.Body().GetAttribute("permissions")
+					if permissionsAttr == nil {
+						continue
+					}
+
+					permissions, ok := literalStringList(permissionsAttr)
+					if !ok {
+						// Permissions given as an expression e.g. var.permissions are represented
+						// as a dynamic "grant" block with a grant per permission
+						dynamicBlock := acpBlock.Body().AppendNewBlock("dynamic", []string{"grant"})
+						dynamicBlock.Body().SetAttributeTraversal("for_each", hcl.Traversal{
+							hcl.TraverseRoot{
+								Name: fmt.Sprintf("toset(%s)", strings.TrimSpace(string(permissionsAttr.Expr().BuildTokens(nil).Bytes()))),
+							},
+						})
+						dynamicBlock.Body().AppendNewline()
 
-					var permissions []string
+						contentBlock := dynamicBlock.Body().AppendNewBlock("content", nil)
+						grantee := contentBlock.Body().AppendNewBlock("grantee", nil)
 
-					for k, v := range grant.Body().Attributes() {
-						// Expected: id, type, uri, permissions
-						if k == "permissions" {
-							for _, t := range v.BuildTokens(nil) {
-								if p := string(t.Bytes); len(p) > 1 && p != k {
-									permissions = append(permissions, p)
-								}
+						for _, k := range sortedAttributeNames(grant.Body()) {
+							if k == "permissions" {
+								continue
 							}
-						} else {
-							grantee.Body().SetAttributeRaw(k, v.Expr().BuildTokens(nil))
+							grantee.Body().SetAttributeRaw(k, grant.Body().GetAttribute(k).Expr().BuildTokens(nil))
 						}
-					}
 
-					if len(permissions) == 0 {
+						contentBlock.Body().SetAttributeTraversal("permission", hcl.Traversal{
+							hcl.TraverseRoot{
+								Name: "grant.value",
+							},
+						})
 						continue
 					}
 
-					grantBlock.Body().SetAttributeValue("permission", cty.StringVal(permissions[0]))
-
-					if len(permissions) > 1 {
-						// Create a new grant block for this permission
-						for _, permission := range permissions[1:] {
-							grantBlock := acpBlock.Body().AppendNewBlock("grant", nil)
-							grantee := grantBlock.Body().AppendNewBlock("grantee", nil)
+					// Create a new grant block for each permission
+					for _, permission := range permissions {
+						grantBlock := acpBlock.Body().AppendNewBlock("grant", nil)
+						grantee := grantBlock.Body().AppendNewBlock("grantee", nil)
 
-							for k, v := range grant.Body().Attributes() {
-								if k == "permissions" {
-									continue
-								}
-								grantee.Body().SetAttributeRaw(k, v.Expr().BuildTokens(nil))
+						for _, k := range sortedAttributeNames(grant.Body()) {
+							if k == "permissions" {
+								continue
 							}
-
-							grantBlock.Body().SetAttributeValue("permission", cty.StringVal(permission))
+							grantee.Body().SetAttributeRaw(k, grant.Body().GetAttribute(k).Expr().BuildTokens(nil))
 						}
+
+						grantBlock.Body().SetAttributeValue("permission", cty.StringVal(permission))
 					}
 				}
 
 				log.Printf("	  ✓ Created %s.%s", ResourceTypeAwsS3BucketAcl, newlabels[1])
 				m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s", ResourceTypeAwsS3BucketAcl, newlabels[1], bucketPath))
+
+				aclResourceBlock = newBlock
 			} // TODO: Account for case where "acl" and "grant" are configured
 		}
 
+		// An ACL which cannot be determined statically may be private, so the public access
+		// of the bucket is not relaxed without grants.
+		if m.ownershipControls && aclResourceBlock != nil && len(grants) == 0 && !isLiteralAcl(aclAttr) {
+			log.Printf("[WARN] Unable to determine whether the %s of %s is private; %s and %s resources are not created",
+				Acl, bucketPath, ResourceTypeAwsS3BucketOwnershipControls, ResourceTypeAwsS3BucketPublicAccessBlock)
+			appendTodoComment(aclResourceBlock.Body(), fmt.Sprintf("Add '%s' and '%s' resources if the '%s' is not private",
+				ResourceTypeAwsS3BucketOwnershipControls, ResourceTypeAwsS3BucketPublicAccessBlock, Acl))
+		}
+
+		// ACLs are disabled by default on new buckets, so applying a non-private ACL requires
+		// object ownership controls, and a public one also a public access block that permits it.
+		if m.ownershipControls && aclResourceBlock != nil && (len(grants) > 0 || isNonPrivateAcl(aclAttr)) {
+			f.Body().AppendNewline()
+
+			ownershipLabels := []string{ResourceTypeAwsS3BucketOwnershipControls.String(), fmt.Sprintf("%s_%s", labels[1], OwnershipControls)}
+			ownershipBlock := f.Body().AppendNewBlock(block.Type(), ownershipLabels)
+
+			ownershipBlock.Body().SetAttributeTraversal("bucket", hcl.Traversal{
+				hcl.TraverseRoot{
+					Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
+				},
+			})
+
+			ruleBlock := ownershipBlock.Body().AppendNewBlock("rule", nil)
+			ruleBlock.Body().SetAttributeValue("object_ownership", cty.StringVal("BucketOwnerPreferred"))
+
+			log.Printf("	  ✓ Created %s.%s", ResourceTypeAwsS3BucketOwnershipControls, ownershipLabels[1])
+
+			dependsOn := []string{strings.Join(ownershipLabels, ".")}
+
+			public, known := isPublicAcl(aclAttr), true
+			for _, grant := range grants {
+				grantPublic, ok := isPublicGrant(grant)
+				public = public || grantPublic
+				known = known && ok
+			}
+
+			if !public && !known {
+				log.Printf("[WARN] Unable to determine whether the %s of %s are public; a %s resource is not created",
+					Grant, bucketPath, ResourceTypeAwsS3BucketPublicAccessBlock)
+				appendTodoComment(aclResourceBlock.Body(), fmt.Sprintf("Add a '%s' resource if the '%s' are public",
+					ResourceTypeAwsS3BucketPublicAccessBlock, Grant))
+			}
+
+			// Only public ACLs are blocked, so the bucket policy remains protected
+			if public {
+				f.Body().AppendNewline()
+
+				publicAccessBlockLabels := []string{ResourceTypeAwsS3BucketPublicAccessBlock.String(), fmt.Sprintf("%s_%s", labels[1], PublicAccessBlock)}
+				publicAccessBlock := f.Body().AppendNewBlock(block.Type(), publicAccessBlockLabels)
+
+				publicAccessBlock.Body().SetAttributeTraversal("bucket", hcl.Traversal{
+					hcl.TraverseRoot{
+						Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
+					},
+				})
+
+				publicAccessBlock.Body().AppendNewline()
+				publicAccessBlock.Body().SetAttributeValue("block_public_acls", cty.False)
+				publicAccessBlock.Body().SetAttributeValue("ignore_public_acls", cty.False)
+
+				log.Printf("	  ✓ Created %s.%s", ResourceTypeAwsS3BucketPublicAccessBlock, publicAccessBlockLabels[1])
+
+				dependsOn = append(dependsOn, strings.Join(publicAccessBlockLabels, "."))
+			}
+
+			// These resources are not recorded in the migrations as they are not expected
+			// to exist for the bucket already and hence cannot be imported.
+			aclResourceBlock.Body().AppendNewline()
+			aclResourceBlock.Body().SetAttributeTraversal("depends_on", hcl.Traversal{
+				hcl.TraverseRoot{
+					Name: fmt.Sprintf("[%s]", strings.Join(dependsOn, ", ")),
+				},
+			})
+		}
+
 		if len(lifecycleRules) > 0 {
 			f.Body().AppendNewline()
 
@@ -417,24 +495,14 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 					}
 				}
 
-				if vTags, ok := m["tags"]; ok {
-					filterBlock := ruleBlock.Body().AppendNewBlock("filter", nil)
-					andBlock := filterBlock.Body().AppendNewBlock("and", nil)
-					andBlock.Body().SetAttributeRaw("tags", vTags.Expr().BuildTokens(nil))
-					if vPrefix, vOk := m["prefix"]; vOk {
-						andBlock.Body().SetAttributeRaw("prefix", vPrefix.Expr().BuildTokens(nil))
-					} else {
-						andBlock.Body().SetAttributeValue("prefix", cty.StringVal(""))
-					}
-				} else if vPrefix, vOk := m["prefix"]; vOk {
-					filterBlock := ruleBlock.Body().AppendNewBlock("filter", nil)
-					filterBlock.Body().SetAttributeRaw("prefix", vPrefix.Expr().BuildTokens(nil))
-				}
+				appendRuleFilterBlock(ruleBlock, m["prefix"], m["tags"])
 
 				for _, b := range lifecycleRuleBlock.Body().Blocks() {
 					// Expected: expiration, noncurrent_version_expiration, transition, noncurrent_version_transition
 					switch b.Type() {
 					case "expiration", "transition":
+						// "date" must be in RFC3339 format in the new resource
+						normalizeLifecycleDate(b, newlabels)
 						ruleBlock.Body().AppendBlock(b)
 					case "noncurrent_version_expiration":
 						nve := ruleBlock.Body().AppendNewBlock("noncurrent_version_expiration", nil)
@@ -541,40 +609,56 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 				}
 			}
 
+			versioningResourcePath = strings.Join(newlabels, ".")
+
 			log.Printf("	  ✓ Created %s.%s", newlabels[1], ResourceTypeAwsS3BucketVersioning)
 			m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s", ResourceTypeAwsS3BucketVersioning, newlabels[1], bucketPath))
 		}
 
 		if objectLockConfig != nil {
-			f.Body().AppendNewline()
-
-			newlabels := []string{ResourceTypeAwsS3BucketObjectLockConfiguration.String(), fmt.Sprintf("%s_%s", labels[1], ObjectLockConfiguration)}
-			newBlock := f.Body().AppendNewBlock(block.Type(), newlabels)
-
-			newBlock.Body().SetAttributeTraversal("bucket", hcl.Traversal{
-				hcl.TraverseRoot{
-					Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
-				},
-			})
-
-			for k, v := range objectLockConfig.Body().Attributes() {
-				// Expected: object_lock_enabled
-				if k != "object_lock_enabled" {
-					continue
+			if v := objectLockConfig.Body().GetAttribute("object_lock_enabled"); v != nil {
+				// This is represented as a boolean "object_lock_enabled" argument of the aws_s3_bucket resource
+				if value, ok := literalString(v); ok {
+					block.Body().SetAttributeValue("object_lock_enabled", cty.BoolVal(value == "Enabled"))
+				} else {
+					block.Body().SetAttributeTraversal("object_lock_enabled", hcl.Traversal{
+						hcl.TraverseRoot{
+							Name: fmt.Sprintf("%s == \"Enabled\"", strings.TrimSpace(string(v.Expr().BuildTokens(nil).Bytes()))),
+						},
+					})
 				}
-				newBlock.Body().SetAttributeRaw("object_lock_enabled", v.Expr().BuildTokens(nil))
+				log.Printf("	  ✓ Set object_lock_enabled in %s", bucketPath)
 			}
 
+			var rules []*hclwrite.Block
 			for _, ob := range objectLockConfig.Body().Blocks() {
 				// we only expect 1 rule as defined in the aws_s3_bucket schema
 				if ob.Type() != "rule" {
 					continue
 				}
-				newBlock.Body().AppendBlock(ob)
+				rules = append(rules, ob)
 			}
 
-			log.Printf("	  ✓ Created %s.%s", ResourceTypeAwsS3BucketObjectLockConfiguration, newlabels[1])
-			m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s", ResourceTypeAwsS3BucketObjectLockConfiguration, newlabels[1], bucketPath))
+			// The new resource only carries the object lock rule
+			if len(rules) > 0 {
+				f.Body().AppendNewline()
+
+				newlabels := []string{ResourceTypeAwsS3BucketObjectLockConfiguration.String(), fmt.Sprintf("%s_%s", labels[1], ObjectLockConfiguration)}
+				newBlock := f.Body().AppendNewBlock(block.Type(), newlabels)
+
+				newBlock.Body().SetAttributeTraversal("bucket", hcl.Traversal{
+					hcl.TraverseRoot{
+						Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
+					},
+				})
+
+				for _, rule := range rules {
+					newBlock.Body().AppendBlock(rule)
+				}
+
+				log.Printf("	  ✓ Created %s.%s", ResourceTypeAwsS3BucketObjectLockConfiguration, newlabels[1])
+				m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s", ResourceTypeAwsS3BucketObjectLockConfiguration, newlabels[1], bucketPath))
+			}
 		}
 
 		if replicationConfig != nil {
@@ -605,18 +689,39 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 					continue
 				}
 
+				var priority, prefix *hclwrite.Attribute
+				var hasDeleteMarkerReplication, hasFilter bool
+
+				for _, innerRuleBlock := range b.Body().Blocks() {
+					if innerRuleBlock.Type() == "filter" {
+						hasFilter = true
+					}
+				}
+
 				for k, v := range b.Body().Attributes() {
 					// Expected: id, prefix, status, priority, delete_marker_replication_status
 					switch k {
-					case "id", "prefix", "status", "priority":
+					case "id", "status":
+						ruleBlock.Body().SetAttributeRaw(k, v.Expr().BuildTokens(nil))
+					case "priority":
+						priority = v
 						ruleBlock.Body().SetAttributeRaw(k, v.Expr().BuildTokens(nil))
+					case "prefix":
+						prefix = v
 					case "delete_marker_replication_status":
 						// This is represented as a block in the new resource
+						hasDeleteMarkerReplication = true
 						deleteMarkerBlock := ruleBlock.Body().AppendNewBlock("delete_marker_replication", nil)
 						deleteMarkerBlock.Body().SetAttributeRaw("status", v.Expr().BuildTokens(nil))
 					}
 				}
 
+				// Rules with a priority or a filter use the V2 replication configuration schema in which
+				// a rule-level prefix is represented in the filter of the new resource
+				if prefix != nil && priority == nil && !hasFilter {
+					ruleBlock.Body().SetAttributeRaw("prefix", prefix.Expr().BuildTokens(nil))
+				}
+
 				for _, innerRuleBlock := range b.Body().Blocks() {
 					// Expected: filter, source_selection_criteria, destination
 					switch innerRuleBlock.Type() {
@@ -675,8 +780,6 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 						}
 
 					case "filter":
-						filterBlock := ruleBlock.Body().AppendNewBlock("filter", nil)
-
 						m := make(map[string]*hclwrite.Attribute)
 
 						for k, v := range innerRuleBlock.Body().Attributes() {
@@ -687,17 +790,15 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 							}
 						}
 
-						if vTags, ok := m["tags"]; ok {
-							andBlock := filterBlock.Body().AppendNewBlock("and", nil)
-							andBlock.Body().SetAttributeRaw("tags", vTags.Expr().BuildTokens(nil))
-							if vPrefix, vOk := m["prefix"]; vOk {
-								andBlock.Body().SetAttributeRaw("prefix", vPrefix.Expr().BuildTokens(nil))
-							} else {
-								andBlock.Body().SetAttributeValue("prefix", cty.StringVal(""))
-							}
-						} else if vPrefix, ok := m["prefix"]; ok {
-							filterBlock.Body().SetAttributeRaw("prefix", vPrefix.Expr().BuildTokens(nil))
+						filterPrefix := m["prefix"]
+						if filterPrefix == nil {
+							filterPrefix = prefix
+						} else if prefix != nil {
+							log.Printf("[WARN] Unable to migrate rule-level prefix of replication rule in %s: filter already has a prefix", bucketPath)
+							appendTodoComment(ruleBlock.Body(), fmt.Sprintf("Add the rule-level prefix %s to the filter", strings.TrimSpace(string(prefix.Expr().BuildTokens(nil).Bytes()))))
 						}
+
+						appendRuleFilterBlock(ruleBlock, filterPrefix, m["tags"])
 					case "source_selection_criteria":
 						sscBlock := ruleBlock.Body().AppendNewBlock("source_selection_criteria", nil)
 
@@ -722,6 +823,28 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 						}
 					}
 				}
+
+				if priority != nil {
+					// The V2 replication configuration schema requires both "filter" and "delete_marker_replication"
+					if !hasFilter {
+						appendRuleFilterBlock(ruleBlock, prefix, nil)
+					}
+
+					if !hasDeleteMarkerReplication {
+						deleteMarkerBlock := ruleBlock.Body().AppendNewBlock("delete_marker_replication", nil)
+						deleteMarkerBlock.Body().SetAttributeValue("status", cty.StringVal("Disabled"))
+					}
+				}
+			}
+
+			if versioningResourcePath != "" {
+				// Versioning must be enabled on the bucket before replication can be configured
+				newBlock.Body().AppendNewline()
+				newBlock.Body().SetAttributeTraversal("depends_on", hcl.Traversal{
+					hcl.TraverseRoot{
+						Name: fmt.Sprintf("[%s]", versioningResourcePath),
+					},
+				})
 			}
 
 			log.Printf("	  ✓ Created %s.%s", ResourceTypeAwsS3BucketReplicationConfiguration, newlabels[1])
@@ -812,6 +935,22 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 				case "redirect_all_requests_to":
 					redirectBlock := newBlock.Body().AppendNewBlock("redirect_all_requests_to", nil)
 
+					// The value can be a hostname or a URL which is represented as "host_name" and "protocol" in the new resource
+					if value, ok := literalString(v); ok && !hasForEach {
+						hostName, protocol, rest := parseRedirectURL(value)
+						redirectBlock.Body().SetAttributeValue("host_name", cty.StringVal(hostName))
+						if protocol != "" {
+							redirectBlock.Body().SetAttributeValue("protocol", cty.StringVal(protocol))
+						}
+						if rest != "" {
+							log.Printf("[WARN] Unable to migrate %q of 'redirect_all_requests_to' value to %s.%s: only a host name and protocol are supported", rest, newlabels[0], newlabels[1])
+							appendTodoComment(redirectBlock.Body(), fmt.Sprintf("Requests were redirected to %q; use 'routing_rule' blocks to redirect to a path, query or fragment", value))
+						}
+						continue
+					}
+
+					log.Printf("[WARN] Unable to determine 'host_name' and 'protocol' in %s.%s from 'redirect_all_requests_to' value", newlabels[0], newlabels[1])
+
 					if hasForEach {
 						val := strings.Replace(string(v.Expr().BuildTokens(nil).Bytes()), "website.value", "each.value", 1)
 						redirectBlock.Body().SetAttributeTraversal("host_name", hcl.Traversal{
@@ -822,14 +961,31 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 					} else {
 						redirectBlock.Body().SetAttributeRaw("host_name", v.Expr().BuildTokens(nil))
 					}
+
+					appendTodoComment(redirectBlock.Body(), "Ensure 'host_name' does not include a protocol e.g. https:// and set 'protocol' if needed")
 				case "routing_rules":
 					var unmarshalledRules []*s3.RoutingRule    // if we can parse string as JSON
 					var customUnmarshalledRules []*RoutingRule // if we can't parse string as JSON, try as YAML (e.g. when jsonencode func is used in terraform)
 
-					routingRulesStr := strings.TrimSpace(string(v.Expr().BuildTokens(nil).Bytes()))
+					// Resolve the value if it can be determined statically e.g. from a literal, file(), templatefile(), locals or variables
+					var routingRulesStr string
+					value, known := m.module.Value(v)
+					if known && !hasForEach && !value.IsNull() && value.Type() == cty.String {
+						routingRulesStr = value.AsString()
+					} else {
+						known = false
+						routingRulesStr = strings.TrimSpace(string(v.Expr().BuildTokens(nil).Bytes()))
+					}
+
 					indexOfOpenBracket := strings.Index(routingRulesStr, "[")
 					indexOfCloseBracket := strings.LastIndex(routingRulesStr, "]")
 
+					if !known && (indexOfOpenBracket == -1 || indexOfCloseBracket == -1) {
+						log.Printf("[INFO] Unable to determine 'routing_rules' value in %s.%s statically; using a dynamic 'routing_rule' block", ResourceTypeAwsS3BucketWebsiteConfiguration, newlabels[1])
+						appendDynamicRoutingRuleBlock(newBlock.Body(), v, hasForEach)
+						continue
+					}
+
 					if indexOfOpenBracket == -1 || indexOfCloseBracket == -1 {
 						log.Printf("[WARN] Unable to set 'routing_rule' in %s.%s.%s as configuration blocks from value", ResourceTypeAwsS3BucketWebsiteConfiguration, labels[1], WebsiteConfiguration)
 						newBlock.Body().AppendUnstructuredTokens(hclwrite.Tokens{
@@ -850,6 +1006,12 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 						}
 					}
 
+					if len(unmarshalledRules) == 0 && len(customUnmarshalledRules) == 0 && !known {
+						log.Printf("[INFO] Unable to determine 'routing_rules' value in %s.%s statically; using a dynamic 'routing_rule' block", ResourceTypeAwsS3BucketWebsiteConfiguration, newlabels[1])
+						appendDynamicRoutingRuleBlock(newBlock.Body(), v, hasForEach)
+						continue
+					}
+
 					if len(unmarshalledRules) == 0 && len(customUnmarshalledRules) == 0 {
 						log.Printf("[WARN] Unable to set 'routing_rule' in %s.%s_%s: no routing rules parsed", ResourceTypeAwsS3BucketWebsiteConfiguration, labels[1], WebsiteConfiguration)
 						newBlock.Body().AppendUnstructuredTokens(hclwrite.Tokens{
@@ -948,3 +1110,204 @@ func (m *ProviderAwsS3BucketMigrator) migrateS3BucketResources(f *hclwrite.File)
 
 	return nil
 }
+
+// normalizeLifecycleDate rewrites a literal "date" argument of an expiration or transition block
+// from the "2006-01-02" format accepted by the aws_s3_bucket resource to the RFC3339 format
+// required by the aws_s3_bucket_lifecycle_configuration resource.
+// Dates that cannot be determined statically are left as-is and flagged for review.
+func normalizeLifecycleDate(b *hclwrite.Block, labels []string) {
+	attr := b.Body().GetAttribute("date")
+	if attr == nil {
+		return
+	}
+
+	value, ok := literalString(attr)
+	if ok {
+		if _, err := time.Parse(time.RFC3339, value); err == nil {
+			return
+		}
+
+		if t, err := time.Parse("2006-01-02", value); err == nil {
+			b.Body().SetAttributeValue("date", cty.StringVal(t.Format(time.RFC3339)))
+			return
+		}
+	}
+
+	log.Printf("[WARN] Unable to convert 'date' in %s.%s.%s to RFC3339 format", labels[0], labels[1], b.Type())
+	appendTodoComment(b.Body(), "Ensure 'date' is in RFC3339 format e.g. 2023-01-13T00:00:00Z")
+}
+
+// appendRuleFilterBlock appends a "filter" block to a lifecycle or replication rule block in the
+// canonical form read back by the provider: a lone "prefix" or single tag is set directly in the filter,
+// multiple predicates are wrapped in an "and" block, and a rule with neither receives an empty filter.
+func appendRuleFilterBlock(ruleBlock *hclwrite.Block, prefix, tags *hclwrite.Attribute) {
+	filterBlock := ruleBlock.Body().AppendNewBlock("filter", nil)
+
+	// The number of tags is unknown if they are not given as an object e.g. var.tags
+	numTags := -1
+	tagItems, ok := objectItems(tags)
+	if tags == nil {
+		numTags = 0
+	} else if ok {
+		numTags = len(tagItems)
+	}
+
+	switch {
+	case numTags == 0 && prefix == nil:
+		return
+	case numTags == 0:
+		filterBlock.Body().SetAttributeRaw("prefix", prefix.Expr().BuildTokens(nil))
+	case numTags == 1 && prefix == nil:
+		tagBlock := filterBlock.Body().AppendNewBlock("tag", nil)
+		tagBlock.Body().SetAttributeValue("key", cty.StringVal(tagItems[0].Key))
+		tagBlock.Body().SetAttributeTraversal("value", hcl.Traversal{
+			hcl.TraverseRoot{
+				Name: tagItems[0].Value,
+			},
+		})
+	default:
+		andBlock := filterBlock.Body().AppendNewBlock("and", nil)
+		if prefix != nil {
+			andBlock.Body().SetAttributeRaw("prefix", prefix.Expr().BuildTokens(nil))
+		}
+		andBlock.Body().SetAttributeRaw("tags", tags.Expr().BuildTokens(nil))
+	}
+}
+
+// parseRedirectURL splits a "redirect_all_requests_to" value of the aws_s3_bucket resource
+// e.g. https://example.com into its host name and protocol, if any. The path, query and fragment
+// of the value, if any, are returned separately as they cannot be represented in the new resource.
+func parseRedirectURL(value string) (string, string, string) {
+	hostName, protocol := value, ""
+	if i := strings.Index(value, "://"); i >= 0 {
+		u, err := url.Parse(value)
+		if err != nil || u.Host == "" {
+			return value, "", ""
+		}
+		hostName, protocol = u.Host, u.Scheme
+		value = value[i+len("://"):]
+	}
+
+	i := strings.IndexAny(value, "/?#")
+	if i < 0 {
+		return hostName, protocol, ""
+	}
+
+	if protocol == "" {
+		hostName = value[:i]
+	}
+
+	// A trailing slash redirects to the same location as the host name alone
+	if value[i:] == "/" {
+		return hostName, protocol, ""
+	}
+
+	return hostName, protocol, value[i:]
+}
+
+// appendDynamicRoutingRuleBlock appends a dynamic "routing_rule" block to the given body which decodes
+// a "routing_rules" JSON document that cannot be determined statically e.g. var.routing_rules.
+// If the website configuration was itself a dynamic block, the value is optional.
+func appendDynamicRoutingRuleBlock(body *hclwrite.Body, v *hclwrite.Attribute, hasForEach bool) {
+	value := strings.TrimSpace(string(v.Expr().BuildTokens(nil).Bytes()))
+	forEach := fmt.Sprintf("jsondecode(%s)", value)
+	if hasForEach {
+		value = strings.Replace(value, "website.value", "each.value", 1)
+		forEach = fmt.Sprintf("try(jsondecode(%s), [])", value)
+	}
+
+	src := fmt.Sprintf(`dynamic "routing_rule" {
+  for_each = %s
+
+  content {
+    dynamic "condition" {
+      for_each = lookup(routing_rule.value, "Condition", null) == null ? [] : [routing_rule.value.Condition]
+
+      content {
+        http_error_code_returned_equals = lookup(condition.value, "HttpErrorCodeReturnedEquals", null)
+        key_prefix_equals               = lookup(condition.value, "KeyPrefixEquals", null)
+      }
+    }
+
+    redirect {
+      host_name               = lookup(routing_rule.value.Redirect, "HostName", null)
+      http_redirect_code      = lookup(routing_rule.value.Redirect, "HttpRedirectCode", null)
+      protocol                = lookup(routing_rule.value.Redirect, "Protocol", null)
+      replace_key_prefix_with = lookup(routing_rule.value.Redirect, "ReplaceKeyPrefixWith", null)
+      replace_key_with        = lookup(routing_rule.value.Redirect, "ReplaceKeyWith", null)
+    }
+  }
+}
+`, forEach)
+
+	f, diags := hclwrite.ParseConfig([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		log.Printf("[WARN] Unable to create dynamic 'routing_rule' block: %s", diags)
+		appendTodoComment(body, "Replace with your 'routing_rule' configuration")
+		return
+	}
+
+	for _, b := range f.Body().Blocks() {
+		body.AppendBlock(b)
+	}
+}
+
+// isLiteralAcl returns whether the given canned ACL can be determined statically.
+func isLiteralAcl(acl *hclwrite.Attribute) bool {
+	if acl == nil {
+		return false
+	}
+
+	_, ok := literalString(acl)
+	return ok
+}
+
+// isNonPrivateAcl returns whether the given canned ACL is known not to be "private".
+func isNonPrivateAcl(acl *hclwrite.Attribute) bool {
+	if acl == nil {
+		return false
+	}
+
+	value, ok := literalString(acl)
+	return ok && value != "private"
+}
+
+// publicAcls are the canned ACLs which grant access to all users or any AWS account,
+// and hence are blocked by the public access block of new buckets.
+var publicAcls = map[string]bool{
+	"public-read":        true,
+	"public-read-write":  true,
+	"authenticated-read": true,
+}
+
+// publicGrantURIs are the URIs of the grantee groups of all users and any AWS account.
+var publicGrantURIs = map[string]bool{
+	"http://acs.amazonaws.com/groups/global/AllUsers":           true,
+	"http://acs.amazonaws.com/groups/global/AuthenticatedUsers": true,
+}
+
+// isPublicAcl returns whether the given canned ACL is known to be public e.g. "public-read".
+func isPublicAcl(acl *hclwrite.Attribute) bool {
+	if acl == nil {
+		return false
+	}
+
+	value, ok := literalString(acl)
+	return ok && publicAcls[value]
+}
+
+// isPublicGrant returns whether the grantee of a "grant" block is a public group, and whether
+// this can be determined statically.
+func isPublicGrant(grant *hclwrite.Block) (bool, bool) {
+	uriAttr := grant.Body().GetAttribute("uri")
+	if uriAttr == nil {
+		return false, true
+	}
+
+	uri, ok := literalString(uriAttr)
+	if !ok {
+		return false, false
+	}
+
+	return publicGrantURIs[uri], true
+}
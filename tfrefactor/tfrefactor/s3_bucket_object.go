@@ -0,0 +1,190 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// s3ObjectDataSourceRenames maps the deprecated S3 object data sources to their replacements.
+var s3ObjectDataSourceRenames = map[string]string{
+	ResourceTypeAwsS3BucketObject:  ResourceTypeAwsS3Object,
+	ResourceTypeAwsS3BucketObjects: ResourceTypeAwsS3Objects,
+}
+
+// s3ObjectMovedProviderMajorVersion is the major version of the provider whose releases can support
+// moving an aws_s3_bucket_object to an aws_s3_object, which also requires Terraform 1.8 or later.
+const s3ObjectMovedProviderMajorVersion = 5
+
+// ProviderAwsS3BucketObjectMigrator renames aws_s3_bucket_object resources and the
+// aws_s3_bucket_object(s) data sources to aws_s3_object(s), available since v4.0.0 of the provider.
+// References to them are rewritten and "moved" blocks are added so that no import is needed.
+type ProviderAwsS3BucketObjectMigrator struct {
+	names            *NameFilter
+	providerVersion  string
+	module           *Module
+	newResourceNames []string
+
+	// addresses of the resources and data sources of the file annotated with "tfrefactor:ignore"
+	ignoredAddresses map[string]bool
+}
+
+func NewProviderAwsS3BucketObjectMigrator(names *NameFilter, providerVersion string, module *Module) (Migrator, error) {
+	return &ProviderAwsS3BucketObjectMigrator{
+		names:           names,
+		providerVersion: providerVersion,
+		module:          module,
+	}, nil
+}
+
+func (m *ProviderAwsS3BucketObjectMigrator) SkipResourceName(address string) bool {
+	if m == nil {
+		return false
+	}
+
+	return m.names.Skip(address)
+}
+
+// ignored returns whether a resource or data source is annotated with "tfrefactor:ignore" in
+// the file being migrated or any other file of its module.
+func (m *ProviderAwsS3BucketObjectMigrator) ignored(address string) bool {
+	return m.ignoredAddresses[address] || m.module.Ignored(address)
+}
+
+func (m *ProviderAwsS3BucketObjectMigrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating (%s) resources: empty file", ResourceTypeAwsS3BucketObject)
+	}
+
+	fileAnnotation := fileIgnoreAnnotation(f)
+	m.ignoredAddresses = make(map[string]bool)
+	for _, block := range f.Body().Blocks() {
+		if blockIgnoreAnnotation(block, fileAnnotation).skipResource() {
+			m.ignoredAddresses[blockAddress(block)] = true
+		}
+	}
+
+	// References can be made from any file in the module, so rewrite them regardless
+	// of whether the resources are declared in this file, unless they are ignored where declared.
+	m.renameReferences(f.Body())
+
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		if len(labels) != 2 || m.SkipResourceName(blockAddress(block)) || m.ignored(blockAddress(block)) {
+			continue
+		}
+
+		switch block.Type() {
+		case "resource":
+			if labels[0] != ResourceTypeAwsS3BucketObject {
+				continue
+			}
+
+			block.SetLabels([]string{ResourceTypeAwsS3Object, labels[1]})
+
+			f.Body().AppendNewline()
+			movedBlock := f.Body().AppendNewBlock("moved", nil)
+			movedBlock.Body().SetAttributeTraversal("from", hcl.Traversal{
+				hcl.TraverseRoot{Name: ResourceTypeAwsS3BucketObject},
+				hcl.TraverseAttr{Name: labels[1]},
+			})
+			movedBlock.Body().SetAttributeTraversal("to", hcl.Traversal{
+				hcl.TraverseRoot{Name: ResourceTypeAwsS3Object},
+				hcl.TraverseAttr{Name: labels[1]},
+			})
+
+			// Moving between resource types is not supported by releases of the provider before v5,
+			// which would recreate the object instead
+			if major := providerMajorVersion(m.providerVersion); major > 0 && major < s3ObjectMovedProviderMajorVersion {
+				log.Printf("[WARN] Moving %s.%s to %s.%s requires Terraform 1.8 or later and a v%d release of the provider supporting it, but the provider version is %s; remove it from the state and import it instead",
+					ResourceTypeAwsS3BucketObject, labels[1], ResourceTypeAwsS3Object, labels[1], s3ObjectMovedProviderMajorVersion, m.providerVersion)
+				appendTodoComment(movedBlock.Body(), fmt.Sprintf("Replace with 'terraform state rm %s.%s' and 'terraform import %s.%s <bucket>/<key>', as provider version %s does not support this move",
+					ResourceTypeAwsS3BucketObject, labels[1], ResourceTypeAwsS3Object, labels[1], m.providerVersion))
+			}
+
+			log.Printf("	  ✓ Moved %s.%s to %s.%s", ResourceTypeAwsS3BucketObject, labels[1], ResourceTypeAwsS3Object, labels[1])
+		case "data":
+			newType, ok := s3ObjectDataSourceRenames[labels[0]]
+			if !ok {
+				continue
+			}
+
+			block.SetLabels([]string{newType, labels[1]})
+
+			log.Printf("	  ✓ Renamed data.%s.%s to data.%s.%s", labels[0], labels[1], newType, labels[1])
+		}
+	}
+
+	return nil
+}
+
+func (m *ProviderAwsS3BucketObjectMigrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
+
+// renameReferences rewrites references to the renamed resources and data sources
+// in all attributes of the given body, including nested blocks.
+func (m *ProviderAwsS3BucketObjectMigrator) renameReferences(body *hclwrite.Body) {
+	for name, attr := range body.Attributes() {
+		// The source address of an existing "moved" block refers to a previous address
+		if name == "from" && body.GetAttribute("to") != nil {
+			continue
+		}
+
+		for _, traversal := range attr.Expr().Variables() {
+			names := traversalNames(traversal)
+
+			switch {
+			case len(names) >= 2 && names[0] == ResourceTypeAwsS3BucketObject:
+				if m.SkipResourceName(strings.Join(names[:2], ".")) || m.ignored(strings.Join(names[:2], ".")) {
+					continue
+				}
+				attr.Expr().RenameVariablePrefix(names[:2], []string{ResourceTypeAwsS3Object, names[1]})
+			case len(names) >= 3 && names[0] == "data":
+				newType, ok := s3ObjectDataSourceRenames[names[1]]
+				if !ok || m.SkipResourceName(strings.Join(names[:3], ".")) || m.ignored(strings.Join(names[:3], ".")) {
+					continue
+				}
+				attr.Expr().RenameVariablePrefix(names[:3], []string{"data", newType, names[2]})
+			}
+		}
+	}
+
+	for _, block := range body.Blocks() {
+		m.renameReferences(block.Body())
+	}
+}
+
+// blockAddress returns the address of a resource or data source block e.g. data.aws_s3_bucket_object.example.
+func blockAddress(block *hclwrite.Block) string {
+	address := strings.Join(block.Labels(), ".")
+	if block.Type() == "data" {
+		return fmt.Sprintf("data.%s", address)
+	}
+	return address
+}
+
+// traversalNames returns the leading names of a traversal e.g. ["aws_s3_bucket_object", "example", "id"]
+// for aws_s3_bucket_object.example.id, stopping at the first index step.
+func traversalNames(traversal *hclwrite.Traversal) []string {
+	var names []string
+	for _, t := range traversal.BuildTokens(nil) {
+		switch t.Type {
+		case hclsyntax.TokenIdent:
+			names = append(names, string(t.Bytes))
+		case hclsyntax.TokenDot:
+			continue
+		default:
+			return names
+		}
+	}
+
+	return names
+}
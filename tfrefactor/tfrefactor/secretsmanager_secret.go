@@ -0,0 +1,202 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// ProviderAwsSecretsManagerSecretMigrator migrates the rotation arguments of aws_secretsmanager_secret
+// resources, removed in v4.0.0 of the provider, to aws_secretsmanager_secret_rotation resources.
+type ProviderAwsSecretsManagerSecretMigrator struct {
+	arguments        *ArgumentFilter
+	names            *NameFilter
+	newResourceNames []string
+
+	// ignore annotation of the block being migrated, if any
+	annotation *ignoreAnnotation
+}
+
+func NewProviderAwsSecretsManagerSecretMigrator(arguments *ArgumentFilter, names *NameFilter) (Migrator, error) {
+	return &ProviderAwsSecretsManagerSecretMigrator{
+		arguments: arguments,
+		names:     names,
+	}, nil
+}
+
+func (m *ProviderAwsSecretsManagerSecretMigrator) SkipResourceName(address string) bool {
+	if m == nil {
+		return false
+	}
+
+	return m.names.Skip(address)
+}
+
+func (m *ProviderAwsSecretsManagerSecretMigrator) SkipArgument(arg string) bool {
+	if m == nil {
+		return false
+	}
+
+	if m.annotation.skipArgument(arg) {
+		return true
+	}
+
+	return m.arguments.Skip(arg)
+}
+
+func (m *ProviderAwsSecretsManagerSecretMigrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating (%s) resources: empty file", ResourceTypeAwsSecretsManagerSecret)
+	}
+
+	fileAnnotation := fileIgnoreAnnotation(f)
+
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		if block.Type() != "resource" || len(labels) != 2 || labels[0] != ResourceTypeAwsSecretsManagerSecret {
+			continue
+		}
+
+		m.annotation = blockIgnoreAnnotation(block, fileAnnotation)
+		if m.SkipResourceName(blockAddress(block)) || m.annotation.skipResource() {
+			continue
+		}
+
+		secretPath := strings.Join(labels, ".")
+		log.Printf("[INFO] Found %s\n", secretPath)
+
+		var rotationEnabled, rotationLambdaArn *hclwrite.Attribute
+		if !m.SkipArgument(RotationEnabled) {
+			rotationEnabled = block.Body().GetAttribute(RotationEnabled)
+		}
+		if !m.SkipArgument(RotationLambdaArn) {
+			rotationLambdaArn = block.Body().GetAttribute(RotationLambdaArn)
+		}
+
+		var rotationRules *hclwrite.Block
+		if !m.SkipArgument(RotationRules) {
+			rotationRules = block.Body().FirstMatchingBlock(RotationRules, nil)
+		}
+
+		if rotationEnabled == nil && rotationLambdaArn == nil && rotationRules == nil {
+			continue
+		}
+
+		// Rotation enabled by an expression e.g. var.enable_rotation is represented as a condition
+		// on the instances of the new resource, unless it depends on the instance of the secret
+		var condition string
+		if _, ok := literalValue(rotationEnabled); rotationEnabled != nil && !ok {
+			if referencesInstance(rotationEnabled) {
+				log.Printf("[WARN] Unable to migrate rotation of %s: '%s' depends on the instance of the secret", secretPath, RotationEnabled)
+				appendTodoComment(block.Body(), fmt.Sprintf("Migrate '%s', '%s' and '%s' to a '%s' resource created only where rotation is enabled",
+					RotationEnabled, RotationLambdaArn, RotationRules, ResourceTypeAwsSecretsManagerSecretRotation))
+				continue
+			}
+
+			condition = strings.TrimSpace(string(rotationEnabled.Expr().BuildTokens(nil).Bytes()))
+			if strings.ContainsAny(condition, " ?") {
+				condition = fmt.Sprintf("(%s)", condition)
+			}
+		}
+
+		// Only arguments which are migrated are removed; skipped arguments are left unchanged
+		if rotationEnabled != nil {
+			block.Body().RemoveAttribute(RotationEnabled)
+		}
+
+		// Rotation which is explicitly disabled does not need a new resource, but its
+		// configuration is left for the user to remove or migrate
+		if v, ok := literalValue(rotationEnabled); ok && v.False() {
+			if rotationLambdaArn != nil || rotationRules != nil {
+				log.Printf("[WARN] Rotation of %s is disabled, but it has a '%s' or '%s' configuration which must be removed or migrated to %s",
+					secretPath, RotationLambdaArn, RotationRules, ResourceTypeAwsSecretsManagerSecretRotation)
+				appendTodoComment(block.Body(), fmt.Sprintf("Rotation is disabled; remove '%s' and '%s' or migrate them to a '%s' resource",
+					RotationLambdaArn, RotationRules, ResourceTypeAwsSecretsManagerSecretRotation))
+				continue
+			}
+
+			log.Printf("	  ✓ Removed disabled rotation from %s", secretPath)
+			continue
+		}
+
+		if rotationLambdaArn != nil {
+			block.Body().RemoveAttribute(RotationLambdaArn)
+		}
+		if rotationRules != nil {
+			block.Body().RemoveBlock(rotationRules)
+		}
+
+		f.Body().AppendNewline()
+
+		newlabels := []string{ResourceTypeAwsSecretsManagerSecretRotation, fmt.Sprintf("%s_%s", labels[1], Rotation)}
+		newBlock := f.Body().AppendNewBlock(block.Type(), newlabels)
+
+		// Account for count and for_each of the secret, creating a rotation per instance
+		// for which rotation is enabled
+		secretID := fmt.Sprintf("%s.%s.id", labels[0], labels[1])
+		if countAttr := block.Body().GetAttribute("count"); countAttr != nil {
+			if condition == "" {
+				newBlock.Body().SetAttributeRaw("count", countAttr.Expr().BuildTokens(nil))
+			} else {
+				newBlock.Body().SetAttributeTraversal("count", hcl.Traversal{
+					hcl.TraverseRoot{
+						Name: fmt.Sprintf("%s ? %s : 0", condition, strings.TrimSpace(string(countAttr.Expr().BuildTokens(nil).Bytes()))),
+					},
+				})
+			}
+			newBlock.Body().AppendNewline()
+			secretID = fmt.Sprintf("%s.%s[count.index].id", labels[0], labels[1])
+		} else if forEachAttr := block.Body().GetAttribute("for_each"); forEachAttr != nil {
+			if condition == "" {
+				newBlock.Body().SetAttributeRaw("for_each", forEachAttr.Expr().BuildTokens(nil))
+			} else {
+				newBlock.Body().SetAttributeTraversal("for_each", hcl.Traversal{
+					hcl.TraverseRoot{
+						Name: fmt.Sprintf("{ for k, v in %s : k => v if %s }", strings.TrimSpace(string(forEachAttr.Expr().BuildTokens(nil).Bytes())), condition),
+					},
+				})
+			}
+			newBlock.Body().AppendNewline()
+			secretID = fmt.Sprintf("%s.%s[each.key].id", labels[0], labels[1])
+		} else if condition != "" {
+			newBlock.Body().SetAttributeTraversal("count", hcl.Traversal{
+				hcl.TraverseRoot{
+					Name: fmt.Sprintf("%s ? 1 : 0", condition),
+				},
+			})
+			newBlock.Body().AppendNewline()
+		}
+
+		newBlock.Body().SetAttributeTraversal("secret_id", hcl.Traversal{
+			hcl.TraverseRoot{
+				Name: secretID,
+			},
+		})
+
+		if rotationLambdaArn != nil {
+			newBlock.Body().SetAttributeRaw(RotationLambdaArn, rotationLambdaArn.Expr().BuildTokens(nil))
+		}
+
+		if rotationRules != nil {
+			newBlock.Body().AppendBlock(rotationRules)
+		} else {
+			log.Printf("[WARN] No %s found in %s; it is required by %s", RotationRules, secretPath, ResourceTypeAwsSecretsManagerSecretRotation)
+			appendTodoComment(newBlock.Body(), fmt.Sprintf("Add your '%s' configuration", RotationRules))
+		}
+
+		log.Printf("	  ✓ Created %s.%s", ResourceTypeAwsSecretsManagerSecretRotation, newlabels[1])
+		m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s", ResourceTypeAwsSecretsManagerSecretRotation, newlabels[1], secretPath))
+	}
+
+	return nil
+}
+
+func (m *ProviderAwsSecretsManagerSecretMigrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
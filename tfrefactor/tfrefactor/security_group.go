@@ -0,0 +1,286 @@
+package tfrefactor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// securityGroupRuleSources maps the source arguments of inline security group rules to the argument
+// of the standalone rule resources, which accept a single source each.
+var securityGroupRuleSources = []struct {
+	Name    string
+	NewName string
+}{
+	{Name: "cidr_blocks", NewName: "cidr_ipv4"},
+	{Name: "ipv6_cidr_blocks", NewName: "cidr_ipv6"},
+	{Name: "prefix_list_ids", NewName: "prefix_list_id"},
+	{Name: "security_groups", NewName: "referenced_security_group_id"},
+}
+
+// securityGroupRuleLookupPrefix is the prefix of the import ID column of rule resources whose
+// security group rule ID must be looked up e.g. with aws ec2 describe-security-group-rules.
+const securityGroupRuleLookupPrefix = "lookup:"
+
+// ProviderAwsSecurityGroupMigrator migrates the inline "ingress" and "egress" rules of aws_security_group
+// resources to aws_vpc_security_group_ingress_rule and aws_vpc_security_group_egress_rule resources,
+// creating a resource per source e.g. CIDR block.
+type ProviderAwsSecurityGroupMigrator struct {
+	arguments        *ArgumentFilter
+	names            *NameFilter
+	newResourceNames []string
+
+	// ignore annotation of the block being migrated, if any
+	annotation *ignoreAnnotation
+}
+
+func NewProviderAwsSecurityGroupMigrator(arguments *ArgumentFilter, names *NameFilter) (Migrator, error) {
+	return &ProviderAwsSecurityGroupMigrator{
+		arguments: arguments,
+		names:     names,
+	}, nil
+}
+
+func (m *ProviderAwsSecurityGroupMigrator) SkipResourceName(address string) bool {
+	if m == nil {
+		return false
+	}
+
+	return m.names.Skip(address)
+}
+
+func (m *ProviderAwsSecurityGroupMigrator) SkipArgument(arg string) bool {
+	if m == nil {
+		return false
+	}
+
+	if m.annotation.skipArgument(arg) {
+		return true
+	}
+
+	return m.arguments.Skip(arg)
+}
+
+func (m *ProviderAwsSecurityGroupMigrator) Migrate(f *hclwrite.File) error {
+	if f == nil || f.Body() == nil {
+		return fmt.Errorf("error migrating (%s) resources: empty file", ResourceTypeAwsSecurityGroup)
+	}
+
+	fileAnnotation := fileIgnoreAnnotation(f)
+
+	for _, block := range f.Body().Blocks() {
+		labels := block.Labels()
+		if block.Type() != "resource" || len(labels) != 2 || labels[0] != ResourceTypeAwsSecurityGroup {
+			continue
+		}
+
+		m.annotation = blockIgnoreAnnotation(block, fileAnnotation)
+		if m.SkipResourceName(blockAddress(block)) || m.annotation.skipResource() {
+			continue
+		}
+
+		securityGroupPath := strings.Join(labels, ".")
+		log.Printf("[INFO] Found %s\n", securityGroupPath)
+
+		// Inline rules and rule resources of the same security group conflict, so the rules
+		// are only migrated if all of them can be
+		if reason := m.unmigratableRulesReason(block); reason != "" {
+			log.Printf("[WARN] Unable to migrate the inline rules of %s: %s; the rules are left unchanged", securityGroupPath, reason)
+			appendTodoComment(block.Body(), fmt.Sprintf("Migrate the inline rules to '%s' and '%s' resources; %s",
+				ResourceTypeAwsVpcSecurityGroupIngressRule, ResourceTypeAwsVpcSecurityGroupEgressRule, reason))
+			continue
+		}
+
+		// Special Attribute Handling i.e. for_each and count
+		hasInstances := block.Body().GetAttribute("count") != nil || block.Body().GetAttribute("for_each") != nil
+
+		counts := map[string]int{}
+
+		for _, subBlock := range block.Body().Blocks() {
+			direction := subBlock.Type()
+			if (direction != Ingress && direction != Egress) || m.SkipArgument(direction) {
+				continue
+			}
+
+			block.Body().RemoveBlock(subBlock)
+
+			for _, newBlock := range m.appendSecurityGroupRuleBlocks(f, subBlock, labels, counts) {
+				if hasInstances {
+					appendTodoComment(newBlock.Body(), fmt.Sprintf("Replace 'security_group_id' argument value with correct instance index e.g. %s.%s[count.index].id", labels[0], labels[1]))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// unmigratableRulesReason returns why the inline rules of a security group cannot all be migrated, if any.
+func (m *ProviderAwsSecurityGroupMigrator) unmigratableRulesReason(block *hclwrite.Block) string {
+	for _, subBlock := range block.Body().Blocks() {
+		direction := subBlock.Type()
+		if direction == "dynamic" && len(subBlock.Labels()) == 1 {
+			if l := subBlock.Labels()[0]; (l == Ingress || l == Egress) && !m.SkipArgument(l) {
+				return fmt.Sprintf("dynamic %s rules cannot be migrated", l)
+			}
+			continue
+		}
+
+		if (direction != Ingress && direction != Egress) || m.SkipArgument(direction) {
+			continue
+		}
+
+		self := subBlock.Body().GetAttribute("self")
+		v, ok := literalValue(self)
+		if self != nil && !ok {
+			return fmt.Sprintf("'self' of an %s rule cannot be determined statically", direction)
+		}
+
+		hasSource := self != nil && !v.IsNull() && v.Type() == cty.Bool && v.True()
+		for _, source := range securityGroupRuleSources {
+			hasSource = hasSource || subBlock.Body().GetAttribute(source.Name) != nil
+		}
+		if !hasSource {
+			return fmt.Sprintf("an %s rule has no sources", direction)
+		}
+	}
+
+	return ""
+}
+
+func (m *ProviderAwsSecurityGroupMigrator) Migrations() []string {
+	if m == nil {
+		return nil
+	}
+	return m.newResourceNames
+}
+
+// appendSecurityGroupRuleBlocks appends a rule resource for each source of an inline "ingress" or "egress"
+// rule block. Sources given as an expression e.g. var.cidr_blocks are represented as a single resource
+// with for_each over the expression.
+func (m *ProviderAwsSecurityGroupMigrator) appendSecurityGroupRuleBlocks(f *hclwrite.File, rule *hclwrite.Block, labels []string, counts map[string]int) []*hclwrite.Block {
+	direction := rule.Type()
+	resourceType := ResourceTypeAwsVpcSecurityGroupIngressRule
+	if direction == Egress {
+		resourceType = ResourceTypeAwsVpcSecurityGroupEgressRule
+	}
+
+	var newBlocks []*hclwrite.Block
+
+	appendRuleBlock := func(source, value string) *hclwrite.Block {
+		f.Body().AppendNewline()
+
+		newlabels := []string{resourceType, fmt.Sprintf("%s_%s_%d", labels[1], direction, counts[direction])}
+		counts[direction]++
+
+		newBlock := f.Body().AppendNewBlock("resource", newlabels)
+		newBlocks = append(newBlocks, newBlock)
+
+		log.Printf("	  ✓ Created %s.%s", resourceType, newlabels[1])
+
+		// Rules are imported by their security group rule ID e.g. sgr-0123456789abcdef0, which is not
+		// in the state of the security group, so the migration describes the rule to look up instead
+		log.Printf("[WARN] Unable to determine import ID of %s.%s: security group rule IDs are not in the state of %s; look them up with 'aws ec2 describe-security-group-rules'",
+			resourceType, newlabels[1], strings.Join(labels, "."))
+		m.newResourceNames = append(m.newResourceNames, fmt.Sprintf("%s.%s,%s,%s", resourceType, newlabels[1], strings.Join(labels, "."), securityGroupRuleLookup(rule, source, value)))
+
+		return newBlock
+	}
+
+	for _, source := range securityGroupRuleSources {
+		attr := rule.Body().GetAttribute(source.Name)
+		if attr == nil {
+			continue
+		}
+
+		if values, ok := literalStringList(attr); ok {
+			for _, value := range values {
+				newBlock := appendRuleBlock(source.NewName, value)
+				m.setSecurityGroupRuleArguments(newBlock, rule, labels)
+				newBlock.Body().SetAttributeValue(source.NewName, cty.StringVal(value))
+			}
+			continue
+		}
+
+		forEach := fmt.Sprintf("toset(%s)", strings.TrimSpace(string(attr.Expr().BuildTokens(nil).Bytes())))
+		newBlock := appendRuleBlock(source.NewName, fmt.Sprintf("each.value of %s", forEach))
+		newBlock.Body().SetAttributeTraversal("for_each", hcl.Traversal{
+			hcl.TraverseRoot{
+				Name: forEach,
+			},
+		})
+		newBlock.Body().AppendNewline()
+		m.setSecurityGroupRuleArguments(newBlock, rule, labels)
+		newBlock.Body().SetAttributeTraversal(source.NewName, hcl.Traversal{
+			hcl.TraverseRoot{
+				Name: "each.value",
+			},
+		})
+	}
+
+	// self is a literal; see unmigratableRulesReason
+	if v, ok := literalValue(rule.Body().GetAttribute("self")); ok && !v.IsNull() && v.Type() == cty.Bool && v.True() {
+		newBlock := appendRuleBlock("referenced_security_group_id", "self")
+		m.setSecurityGroupRuleArguments(newBlock, rule, labels)
+		newBlock.Body().SetAttributeTraversal("referenced_security_group_id", hcl.Traversal{
+			hcl.TraverseRoot{
+				Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
+			},
+		})
+	}
+
+	return newBlocks
+}
+
+// securityGroupRuleLookup returns the import ID column of the migration of a rule resource, describing the
+// rule to look up e.g. "lookup:ingress protocol=tcp from_port=443 to_port=443 cidr_ipv4=10.0.0.0/16".
+// Values are written without whitespace or commas so that the migration remains a valid CSV row.
+func securityGroupRuleLookup(rule *hclwrite.Block, source, value string) string {
+	sanitize := strings.NewReplacer(",", ";", `"`, "")
+	fields := []string{rule.Type()}
+	for _, k := range []string{"protocol", "from_port", "to_port"} {
+		if attr := rule.Body().GetAttribute(k); attr != nil {
+			v := strings.Join(strings.Fields(string(attr.Expr().BuildTokens(nil).Bytes())), "")
+			fields = append(fields, fmt.Sprintf("%s=%s", k, sanitize.Replace(v)))
+		}
+	}
+	fields = append(fields, fmt.Sprintf("%s=%s", source, sanitize.Replace(strings.Join(strings.Fields(value), "_"))))
+
+	return securityGroupRuleLookupPrefix + strings.Join(fields, " ")
+}
+
+// setSecurityGroupRuleArguments sets the arguments of a rule resource shared by all sources of an inline rule.
+func (m *ProviderAwsSecurityGroupMigrator) setSecurityGroupRuleArguments(newBlock *hclwrite.Block, rule *hclwrite.Block, labels []string) {
+	newBlock.Body().SetAttributeTraversal("security_group_id", hcl.Traversal{
+		hcl.TraverseRoot{
+			Name: fmt.Sprintf("%s.%s.id", labels[0], labels[1]),
+		},
+	})
+
+	if description := rule.Body().GetAttribute("description"); description != nil {
+		newBlock.Body().SetAttributeRaw("description", description.Expr().BuildTokens(nil))
+	}
+
+	protocol := rule.Body().GetAttribute("protocol")
+	if protocol == nil {
+		return
+	}
+
+	// All protocols are represented as "-1", which does not accept a port range
+	if v := strings.Trim(strings.TrimSpace(string(protocol.Expr().BuildTokens(nil).Bytes())), `"`); v == "-1" || v == "all" {
+		newBlock.Body().SetAttributeValue("ip_protocol", cty.StringVal("-1"))
+		return
+	}
+
+	newBlock.Body().SetAttributeRaw("ip_protocol", protocol.Expr().BuildTokens(nil))
+
+	for _, k := range []string{"from_port", "to_port"} {
+		if attr := rule.Body().GetAttribute(k); attr != nil {
+			newBlock.Body().SetAttributeRaw(k, attr.Expr().BuildTokens(nil))
+		}
+	}
+}
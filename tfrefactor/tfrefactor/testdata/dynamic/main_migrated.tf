@@ -32,8 +32,30 @@ resource "aws_s3_bucket_website_configuration" "a_website_configuration" {
   }
   redirect_all_requests_to {
     host_name = lookup(each.value, "redirect_all_requests_to", null)
+    # TODO: Ensure 'host_name' does not include a protocol e.g. https:// and set 'protocol' if needed
+  }
+  dynamic "routing_rule" {
+    for_each = try(jsondecode(lookup(each.value, "routing_rules", null)), [])
+
+    content {
+      dynamic "condition" {
+        for_each = lookup(routing_rule.value, "Condition", null) == null ? [] : [routing_rule.value.Condition]
+
+        content {
+          http_error_code_returned_equals = lookup(condition.value, "HttpErrorCodeReturnedEquals", null)
+          key_prefix_equals               = lookup(condition.value, "KeyPrefixEquals", null)
+        }
+      }
+
+      redirect {
+        host_name               = lookup(routing_rule.value.Redirect, "HostName", null)
+        http_redirect_code      = lookup(routing_rule.value.Redirect, "HttpRedirectCode", null)
+        protocol                = lookup(routing_rule.value.Redirect, "Protocol", null)
+        replace_key_prefix_with = lookup(routing_rule.value.Redirect, "ReplaceKeyPrefixWith", null)
+        replace_key_with        = lookup(routing_rule.value.Redirect, "ReplaceKeyWith", null)
+      }
+    }
   }
-  # TODO: Replace with your 'routing_rule' configuration
   index_document {
     suffix = lookup(each.value, "index_document", null)
   }
@@ -58,6 +58,7 @@ resource "aws_s3_bucket" "example" {
 
 
 
+  object_lock_enabled = true
 }
 resource "aws_s3_bucket_acl" "b_acl" {
   bucket = aws_s3_bucket.b.id
@@ -145,7 +146,7 @@ resource "aws_s3_bucket_lifecycle_configuration" "example_lifecycle_configuratio
       prefix = "path2/"
     }
     expiration {
-      date = "2016-01-12"
+      date = "2016-01-12T00:00:00Z"
     }
   }
   rule {
@@ -157,7 +158,6 @@ resource "aws_s3_bucket_lifecycle_configuration" "example_lifecycle_configuratio
           "tagKey"    = "tagValue"
           "terraform" = "hashicorp"
         }
-        prefix = ""
       }
     }
     transition {
@@ -169,11 +169,9 @@ resource "aws_s3_bucket_lifecycle_configuration" "example_lifecycle_configuratio
     id     = "id6"
     status = "Enabled"
     filter {
-      and {
-        tags = {
-          "tagKey" = "tagValue"
-        }
-        prefix = ""
+      tag {
+        key   = "tagKey"
+        value = "tagValue"
       }
     }
     transition {
@@ -218,8 +216,7 @@ resource "aws_s3_bucket_versioning" "example_versioning" {
 }
 
 resource "aws_s3_bucket_object_lock_configuration" "example_object_lock_configuration" {
-  bucket              = aws_s3_bucket.example.id
-  object_lock_enabled = "Enabled"
+  bucket = aws_s3_bucket.example.id
   rule {
     default_retention {
       mode = "COMPLIANCE"
@@ -267,11 +264,9 @@ resource "aws_s3_bucket_replication_configuration" "example_replication_configur
     priority = 2
     status   = "Enabled"
     filter {
-      and {
-        tags = {
-          Key2 = "Value2"
-        }
-        prefix = ""
+      tag {
+        key   = "Key2"
+        value = "Value2"
       }
     }
     destination {
@@ -290,7 +285,12 @@ resource "aws_s3_bucket_replication_configuration" "example_replication_configur
         }
       }
     }
+    delete_marker_replication {
+      status = "Disabled"
+    }
   }
+
+  depends_on = [aws_s3_bucket_versioning.example_versioning]
 }
 
 resource "aws_s3_bucket_server_side_encryption_configuration" "example_server_side_encryption_configuration" {
@@ -15,7 +15,7 @@ resource "aws_s3_bucket_lifecycle_configuration" "example_lifecycle_configuratio
       prefix = "path2/"
     }
     expiration {
-      date = "2016-01-12"
+      date = "2016-01-12T00:00:00Z"
     }
   }
   rule {
@@ -27,7 +27,6 @@ resource "aws_s3_bucket_lifecycle_configuration" "example_lifecycle_configuratio
           "tagKey"    = "tagValue"
           "terraform" = "hashicorp"
         }
-        prefix = ""
       }
     }
     transition {
@@ -39,11 +38,9 @@ resource "aws_s3_bucket_lifecycle_configuration" "example_lifecycle_configuratio
     id     = "id6"
     status = "Enabled"
     filter {
-      and {
-        tags = {
-          "tagKey" = "tagValue"
-        }
-        prefix = ""
+      tag {
+        key   = "tagKey"
+        value = "tagValue"
       }
     }
     transition {
@@ -1,10 +1,10 @@
 resource "aws_s3_bucket" "example" {
   bucket = "my-example-bucket"
 
+  object_lock_enabled = true
 }
 resource "aws_s3_bucket_object_lock_configuration" "example_object_lock_configuration" {
-  bucket              = aws_s3_bucket.example.id
-  object_lock_enabled = "Enabled"
+  bucket = aws_s3_bucket.example.id
   rule {
     default_retention {
       mode = "COMPLIANCE"